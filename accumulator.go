@@ -0,0 +1,71 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "sync"
+
+// AccumulatorGroup gives each of a group of worker goroutines its own
+// local Set to add elements to without any locking, and merges all the
+// local Sets when the work is done. This suits embarrassingly parallel
+// deduplication where even a concurrent set would be a bottleneck, e.g.,
+//
+//	group := set.NewAccumulatorGroup[string]()
+//	for _, chunk := range chunks {
+//		local := group.Local()
+//		wg.Add(1)
+//		go func() {
+//			defer wg.Done()
+//			for _, id := range chunk {
+//				local.Add(id)
+//			}
+//		}()
+//	}
+//	wg.Wait()
+//	ids := group.Finish()
+type AccumulatorGroup[E comparable] struct {
+	mutex  sync.Mutex
+	locals []*Set[E]
+}
+
+// NewAccumulatorGroup returns a new empty AccumulatorGroup.
+func NewAccumulatorGroup[E comparable]() *AccumulatorGroup[E] {
+	return &AccumulatorGroup[E]{}
+}
+
+// Local returns a new Set belonging to this AccumulatorGroup. The Set must
+// only be used by one goroutine at a time (normally a single worker), and
+// not at all once [AccumulatorGroup.Finish] is called. It is safe to call
+// Local concurrently.
+func (me *AccumulatorGroup[E]) Local() *Set[E] {
+	local := New[E]()
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	me.locals = append(me.locals, &local)
+	return &local
+}
+
+// Finish returns a Set that is the union of all the group's local Sets
+// and empties the group so it can be reused. It must only be called once
+// all the workers have finished with their local Sets. To avoid copying,
+// the largest local Set is reused as the result.
+func (me *AccumulatorGroup[E]) Finish() Set[E] {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	if len(me.locals) == 0 {
+		return New[E]()
+	}
+	largest := 0
+	for i, local := range me.locals {
+		if local.Len() > me.locals[largest].Len() {
+			largest = i
+		}
+	}
+	result := *me.locals[largest]
+	for i, local := range me.locals {
+		if i != largest {
+			result.Unite(*local)
+		}
+	}
+	me.locals = nil
+	return result
+}
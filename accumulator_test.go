@@ -0,0 +1,41 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAccumulatorGroup(t *testing.T) {
+	group := NewAccumulatorGroup[int]()
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local := group.Local()
+			for i := range 1000 {
+				local.Add((i * (w + 1)) % 2000)
+			}
+		}()
+	}
+	wg.Wait()
+	s := group.Finish()
+	expected := New[int]()
+	for w := range 8 {
+		for i := range 1000 {
+			expected.Add((i * (w + 1)) % 2000)
+		}
+	}
+	if !s.Equal(expected) {
+		t.Errorf("expected %d elements, got %d", expected.Len(), s.Len())
+	}
+	e := group.Finish()
+	if !e.IsEmpty() {
+		t.Errorf("expected reused group to be empty, got %v", e)
+	}
+	local := group.Local()
+	local.Add(7)
+	u := group.Finish()
+	check(sortedStr(u), u.Len(), "{7}", 1, t)
+}
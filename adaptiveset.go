@@ -0,0 +1,311 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+	"strings"
+)
+
+type adaptiveMode uint8
+
+const (
+	adaptiveSmall adaptiveMode = iota
+	adaptiveBitmap
+	adaptiveHash
+)
+
+const (
+	adaptiveSmallMax = 32 // most elements held in small mode
+	// A bitmap is used if it needs at most this many bits per element...
+	adaptiveToBitmap = 64
+	// ...and abandoned if it would need more than this many.
+	adaptiveFromBitmap = 2 * adaptiveToBitmap
+)
+
+// AdaptiveSet is a set of integers which transparently changes its
+// representation to suit its contents: a small sorted slice for up to a
+// few dozen elements, a bitmap when the elements are dense enough that a
+// bitmap needs no more than a word per element, and otherwise a hash map
+// (i.e., a [Set]). So users get near-optimal memory use without having to
+// choose a backend.
+type AdaptiveSet[E Integer] struct {
+	mode  adaptiveMode
+	small []E // adaptiveSmall: sorted elements
+	base  E   // adaptiveBitmap: bit 0 of words[0] is for base
+	words []uint64
+	count int    // adaptiveBitmap: number of set bits
+	hash  Set[E] // adaptiveHash
+	lo    E      // adaptiveHash: the smallest element ever added
+	hi    E      // adaptiveHash: the largest element ever added
+}
+
+// NewAdaptive returns a new AdaptiveSet containing the given elements (if
+// any). If no elements are given, the type must be specified since it
+// can't be inferred.
+func NewAdaptive[E Integer](elements ...E) AdaptiveSet[E] {
+	var set AdaptiveSet[E]
+	set.Add(elements...)
+	return set
+}
+
+// Add adds the given element(s) to the AdaptiveSet.
+func (me *AdaptiveSet[E]) Add(elements ...E) {
+	for _, element := range elements {
+		switch me.mode {
+		case adaptiveSmall:
+			i, found := slices.BinarySearch(me.small, element)
+			if !found {
+				me.small = slices.Insert(me.small, i, element)
+				if len(me.small) > adaptiveSmallMax {
+					me.fromSmall()
+				}
+			}
+		case adaptiveBitmap:
+			me.addToBitmap(element)
+		case adaptiveHash:
+			me.addToHash(element)
+		}
+	}
+	if debug {
+		me.checkInvariants()
+	}
+}
+
+// Delete deletes the given element(s) from the AdaptiveSet.
+func (me *AdaptiveSet[E]) Delete(elements ...E) {
+	for _, element := range elements {
+		switch me.mode {
+		case adaptiveSmall:
+			if i, found := slices.BinarySearch(me.small, element); found {
+				me.small = slices.Delete(me.small, i, i+1)
+			}
+		case adaptiveBitmap:
+			if i, ok := me.bit(element); ok &&
+				me.words[i/64]&(1<<(i%64)) != 0 {
+				me.words[i/64] &^= 1 << (i % 64)
+				me.count--
+			}
+		case adaptiveHash:
+			delete(me.hash.set, element)
+		}
+	}
+	if me.mode != adaptiveSmall && me.Len() <= adaptiveSmallMax/2 {
+		me.toSmall()
+	}
+	if debug {
+		me.checkInvariants()
+	}
+}
+
+// Clear deletes all the elements in the AdaptiveSet.
+func (me *AdaptiveSet[E]) Clear() { *me = AdaptiveSet[E]{} }
+
+// Len returns the number of elements in the AdaptiveSet.
+func (me *AdaptiveSet[E]) Len() int {
+	switch me.mode {
+	case adaptiveBitmap:
+		return me.count
+	case adaptiveHash:
+		return me.hash.Len()
+	}
+	return len(me.small)
+}
+
+// IsEmpty returns true if there are no elements in the AdaptiveSet;
+// otherwise returns false.
+func (me *AdaptiveSet[E]) IsEmpty() bool { return me.Len() == 0 }
+
+// Contains returns true if element is in the AdaptiveSet; otherwise
+// returns false.
+func (me *AdaptiveSet[E]) Contains(element E) bool {
+	switch me.mode {
+	case adaptiveBitmap:
+		i, ok := me.bit(element)
+		return ok && me.words[i/64]&(1<<(i%64)) != 0
+	case adaptiveHash:
+		return me.hash.Contains(element)
+	}
+	_, found := slices.BinarySearch(me.small, element)
+	return found
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+// The elements are in ascending order unless the AdaptiveSet is sparse
+// enough to be using a hash map, in which case they are in no particular
+// order.
+func (me *AdaptiveSet[E]) All() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		switch me.mode {
+		case adaptiveSmall:
+			for _, element := range me.small {
+				if !yield(element) {
+					return
+				}
+			}
+		case adaptiveBitmap:
+			for i, word := range me.words {
+				for word != 0 {
+					j := bits.TrailingZeros64(word)
+					word &= word - 1
+					if !yield(me.base + E(i*64+j)) {
+						return
+					}
+				}
+			}
+		case adaptiveHash:
+			for element := range me.hash.set {
+				if !yield(element) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ToSlice returns this AdaptiveSet's elements as a sorted slice.
+func (me *AdaptiveSet[E]) ToSlice() []E {
+	slice := slices.AppendSeq(make([]E, 0, me.Len()), me.All())
+	if me.mode == adaptiveHash {
+		slices.Sort(slice)
+	}
+	return slice
+}
+
+// String returns a human readable string representation of the
+// AdaptiveSet with its elements in ascending order.
+func (me *AdaptiveSet[E]) String() string {
+	var out strings.Builder
+	out.WriteByte('{')
+	sep := ""
+	for _, element := range me.ToSlice() {
+		fmt.Fprintf(&out, "%s%v", sep, element)
+		sep = " "
+	}
+	out.WriteByte('}')
+	return out.String()
+}
+
+// bit returns element's bit index (and true) if it is in the bitmap's
+// range; otherwise returns 0 and false.
+func (me *AdaptiveSet[E]) bit(element E) (uint64, bool) {
+	if element < me.base {
+		return 0, false
+	}
+	i := uint64(element) - uint64(me.base)
+	return i, i < uint64(len(me.words))*64
+}
+
+// top returns the largest element the bitmap's range covers.
+func (me *AdaptiveSet[E]) top() E {
+	return me.base + E(min(uint64(len(me.words))*64-1,
+		span(me.base, maxInteger[E]())))
+}
+
+// span returns hi - lo (where lo <= hi) without overflowing.
+func span[E Integer](lo, hi E) uint64 { return uint64(hi) - uint64(lo) }
+
+// suitsBitmap returns true if n elements spread over [lo, hi] would need
+// at most bitsPerElement bits each in a bitmap.
+func suitsBitmap[E Integer](lo, hi E, n int, bitsPerElement uint64) bool {
+	d := span(lo, hi)
+	return d < uint64(n)*bitsPerElement && d < 1<<40
+}
+
+func (me *AdaptiveSet[E]) fromSmall() {
+	lo, hi := me.small[0], me.small[len(me.small)-1]
+	elements := me.small
+	me.small = nil
+	if suitsBitmap(lo, hi, len(elements), adaptiveToBitmap) {
+		me.toBitmap(lo, hi, elements)
+	} else {
+		me.toHash(lo, hi, elements)
+	}
+}
+
+func (me *AdaptiveSet[E]) toSmall() {
+	elements := me.ToSlice()
+	*me = AdaptiveSet[E]{small: elements}
+}
+
+func (me *AdaptiveSet[E]) toBitmap(lo, hi E, elements []E) {
+	*me = AdaptiveSet[E]{mode: adaptiveBitmap, base: lo,
+		words: make([]uint64, span(lo, hi)/64+1)}
+	for _, element := range elements {
+		me.addToBitmap(element)
+	}
+}
+
+func (me *AdaptiveSet[E]) toHash(lo, hi E, elements []E) {
+	*me = AdaptiveSet[E]{mode: adaptiveHash, lo: lo, hi: hi,
+		hash: Set[E]{set: make(map[E]struct{}, len(elements))}}
+	me.hash.Add(elements...)
+}
+
+func (me *AdaptiveSet[E]) addToBitmap(element E) {
+	i, ok := me.bit(element)
+	if !ok {
+		lo := min(element, me.base)
+		hi := max(element, me.top())
+		if !suitsBitmap(lo, hi, me.count+1, adaptiveFromBitmap) {
+			elements := me.ToSlice()
+			me.toHash(elements[0], elements[len(elements)-1], elements)
+			me.addToHash(element)
+			return
+		}
+		me.regrow(lo, hi)
+		i, _ = me.bit(element)
+	}
+	if me.words[i/64]&(1<<(i%64)) == 0 {
+		me.words[i/64] |= 1 << (i % 64)
+		me.count++
+	}
+}
+
+// regrow re-makes the bitmap to cover at least [lo, hi] with some slack
+// in the direction of growth so that repeated growth is amortized.
+func (me *AdaptiveSet[E]) regrow(lo, hi E) {
+	slack := uint64(len(me.words)/4) * 64
+	if lo < me.base {
+		lo -= E(min(slack, span(minInteger[E](), lo)))
+	} else {
+		hi += E(min(slack, span(hi, maxInteger[E]())))
+	}
+	elements := me.ToSlice()
+	me.toBitmap(lo, hi, elements)
+}
+
+func (me *AdaptiveSet[E]) addToHash(element E) {
+	if _, ok := me.hash.set[element]; ok {
+		return
+	}
+	me.hash.set[element] = struct{}{}
+	me.lo, me.hi = min(me.lo, element), max(me.hi, element)
+	n := len(me.hash.set)
+	// Only check (amortized) every time the length doubles.
+	if n&(n-1) == 0 && suitsBitmap(me.lo, me.hi, n, adaptiveToBitmap/2) {
+		elements := me.hash.ToSlice()
+		slices.Sort(elements)
+		me.toBitmap(elements[0], elements[len(elements)-1], elements)
+	}
+}
+
+func minInteger[E Integer]() E {
+	if isSigned[E]() {
+		return (E(0) - 1) << (bitsOf[E]() - 1)
+	}
+	return 0
+}
+
+func maxInteger[E Integer]() E { return ^minInteger[E]() }
+
+func bitsOf[E Integer]() int {
+	n := 0
+	for x := E(1); x != 0; x <<= 1 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,116 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestAdaptiveSetModes(t *testing.T) {
+	s := NewAdaptive(5, 3, 9, 3)
+	check(s.String(), s.Len(), "{3 5 9}", 3, t)
+	if s.mode != adaptiveSmall {
+		t.Errorf("expected small mode, got %d", s.mode)
+	}
+	for i := range 1000 {
+		s.Add(i)
+	}
+	if s.mode != adaptiveBitmap || s.Len() != 1000 {
+		t.Errorf("expected bitmap of 1000, got %d %d", s.mode, s.Len())
+	}
+	s.Add(1 << 30)
+	if s.mode != adaptiveHash || s.Len() != 1001 {
+		t.Errorf("expected hash of 1001, got %d %d", s.mode, s.Len())
+	}
+	s.Delete(1 << 30)
+	for i := range 990 {
+		s.Delete(i)
+	}
+	if s.mode != adaptiveSmall || s.Len() != 10 {
+		t.Errorf("expected small of 10, got %d %d", s.mode, s.Len())
+	}
+	check(s.String(), s.Len(), "{990 991 992 993 994 995 996 997 998 999}",
+		10, t)
+	u := NewAdaptive[int]()
+	for i := range 100 {
+		u.Add(i * 1000000)
+	}
+	if u.mode != adaptiveHash {
+		t.Errorf("expected sparse set to use hash, got %d", u.mode)
+	}
+	for i := range 100 {
+		u.Delete(i * 1000000)
+	}
+	for i := range 200 {
+		u.Add(i * 3)
+	}
+	if u.mode != adaptiveBitmap || u.Len() != 200 {
+		t.Errorf("expected dense set to use bitmap, got %d", u.mode)
+	}
+	u.Clear()
+	if !u.IsEmpty() || u.mode != adaptiveSmall {
+		t.Errorf("expected empty small set, got %v", u.String())
+	}
+}
+
+func TestAdaptiveSetExtremes(t *testing.T) {
+	s := NewAdaptive[int8]()
+	for i := math.MinInt8; i <= math.MaxInt8; i++ {
+		s.Add(int8(i))
+	}
+	if s.Len() != 256 || !s.Contains(math.MinInt8) ||
+		!s.Contains(math.MaxInt8) || s.mode != adaptiveBitmap {
+		t.Errorf("expected all 256 int8s in a bitmap, got %d", s.Len())
+	}
+	u := NewAdaptive[uint64]()
+	for i := range uint64(40) {
+		u.Add(math.MaxUint64 - i)
+	}
+	u.Add(0)
+	if u.Len() != 41 || !u.Contains(math.MaxUint64) || !u.Contains(0) {
+		t.Errorf("expected 41 uint64s, got %d", u.Len())
+	}
+	if minInteger[int16]() != math.MinInt16 ||
+		maxInteger[int16]() != math.MaxInt16 ||
+		maxInteger[uint32]() != math.MaxUint32 {
+		t.Error("unexpected integer limits")
+	}
+}
+
+func TestAdaptiveSetRandom(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	s := NewAdaptive[int]()
+	u := New[int]()
+	for round := range 20000 {
+		spread := []int{50, 5000, 1 << 30}[round/2000%3]
+		x := rng.IntN(spread) - spread/2
+		if rng.IntN(3) == 0 {
+			s.Delete(x)
+			u.Delete(x)
+		} else {
+			s.Add(x)
+			u.Add(x)
+		}
+		if s.Len() != u.Len() || s.Contains(x) != u.Contains(x) {
+			t.Fatalf("%d: expected %d elements, got %d", round, u.Len(),
+				s.Len())
+		}
+	}
+	expected := slices.Sorted(u.All())
+	if got := s.ToSlice(); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	n := 0
+	for range s.All() {
+		n++
+	}
+	if n != u.Len() {
+		t.Errorf("expected %d, got %d", u.Len(), n)
+	}
+	if fmt.Sprint(s.ToSlice()) != fmt.Sprint(expected) {
+		t.Error("unexpected ToSlice order")
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+// Package benchmarks holds the set package's benchmarks and performance
+// budget; it has no API. Run the benchmarks with, e.g.,
+//
+//	go test -run=^$ -bench=. -count=10 ./benchmarks >old.txt
+//
+// and compare runs before and after a change with benchstat (sub-benchmark
+// names use benchstat's key=value form, e.g., Contains/type=int/n=1000).
+//
+// Timings vary between machines so they aren't checked, but allocations
+// don't, so the tests (which run with the ordinary go test) enforce this
+// budget of allocations per operation:
+//
+//   - Set.Contains, SortedSet.Contains, FrozenStringSet.Contains, and
+//     SyncSet.Contains: 0
+//   - Set.Add of an element that is already present: 0
+//   - Set.Union, Set.Intersection, Set.Difference, and
+//     Set.SymmetricDifference: no more than Set.Clone of the result (which
+//     sizes its map exactly), i.e., the result must not grow through
+//     repeated rehashing
+package benchmarks
@@ -0,0 +1,295 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package benchmarks
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mark-summerfield/set"
+)
+
+var sizes = []int{10, 1000, 100_000}
+
+func ints(n, offset int) []int {
+	elements := make([]int, n)
+	for i := range elements {
+		elements[i] = i + offset
+	}
+	return elements
+}
+
+func strs(n, offset int) []string {
+	elements := make([]string, n)
+	for i := range elements {
+		elements[i] = "element-" + strconv.Itoa(i+offset)
+	}
+	return elements
+}
+
+func name(kind string, n int) string {
+	return fmt.Sprintf("type=%s/n=%d", kind, n)
+}
+
+func BenchmarkAdd(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(name("int", n), func(b *testing.B) { benchAdd(b, ints(n, 0)) })
+		b.Run(name("string", n), func(b *testing.B) {
+			benchAdd(b, strs(n, 0))
+		})
+	}
+}
+
+func benchAdd[E comparable](b *testing.B, elements []E) {
+	b.ReportAllocs()
+	for range b.N {
+		s := set.New[E]()
+		s.Add(elements...)
+	}
+}
+
+func BenchmarkContains(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(name("int", n), func(b *testing.B) {
+			benchContains(b, ints(n, 0))
+		})
+		b.Run(name("string", n), func(b *testing.B) {
+			benchContains(b, strs(n, 0))
+		})
+	}
+}
+
+func benchContains[E comparable](b *testing.B, elements []E) {
+	s := set.New(elements...)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		s.Contains(elements[i%len(elements)])
+	}
+}
+
+func BenchmarkSortedContains(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(name("int", n), func(b *testing.B) {
+			elements := ints(n, 0)
+			s := set.NewSorted(elements...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				s.Contains(elements[i%n])
+			}
+		})
+	}
+}
+
+// The algebra benchmarks use operands that half overlap.
+
+func BenchmarkUnion(b *testing.B) {
+	benchAlgebra(b, func(x, y *set.Set[int]) set.Set[int] {
+		return x.Union(*y)
+	}, func(x, y *set.Set[string]) set.Set[string] {
+		return x.Union(*y)
+	})
+}
+
+func BenchmarkIntersection(b *testing.B) {
+	benchAlgebra(b, func(x, y *set.Set[int]) set.Set[int] {
+		return x.Intersection(*y)
+	}, func(x, y *set.Set[string]) set.Set[string] {
+		return x.Intersection(*y)
+	})
+}
+
+func BenchmarkDifference(b *testing.B) {
+	benchAlgebra(b, func(x, y *set.Set[int]) set.Set[int] {
+		return x.Difference(*y)
+	}, func(x, y *set.Set[string]) set.Set[string] {
+		return x.Difference(*y)
+	})
+}
+
+func BenchmarkSymmetricDifference(b *testing.B) {
+	benchAlgebra(b, func(x, y *set.Set[int]) set.Set[int] {
+		return x.SymmetricDifference(*y)
+	}, func(x, y *set.Set[string]) set.Set[string] {
+		return x.SymmetricDifference(*y)
+	})
+}
+
+func benchAlgebra(b *testing.B, fi func(x, y *set.Set[int]) set.Set[int],
+	fs func(x, y *set.Set[string]) set.Set[string]) {
+	for _, n := range sizes {
+		b.Run(name("int", n), func(b *testing.B) {
+			x, y := set.New(ints(n, 0)...), set.New(ints(n, n/2)...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				fi(&x, &y)
+			}
+		})
+		b.Run(name("string", n), func(b *testing.B) {
+			x, y := set.New(strs(n, 0)...), set.New(strs(n, n/2)...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				fs(&x, &y)
+			}
+		})
+	}
+}
+
+func BenchmarkClone(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(name("int", n), func(b *testing.B) {
+			s := set.New(ints(n, 0)...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				s.Clone()
+			}
+		})
+	}
+}
+
+func TestAllocationBudget(t *testing.T) {
+	const n = 1000
+	elements := ints(n, 0)
+	s := set.New(elements...)
+	z := set.NewSorted(elements...)
+	f := set.NewFrozenStringSet(strs(n, 0)...)
+	y := set.NewSync(elements...)
+	for _, c := range []struct {
+		name string
+		f    func()
+	}{
+		{"Set.Contains", func() { s.Contains(n / 2) }},
+		{"SortedSet.Contains", func() { z.Contains(n / 2) }},
+		{"FrozenStringSet.Contains", func() { f.Contains("element-500") }},
+		{"SyncSet.Contains", func() { y.Contains(n / 2) }},
+		{"Set.Add present", func() { s.Add(n / 2) }},
+	} {
+		if allocs := testing.AllocsPerRun(100, c.f); allocs != 0 {
+			t.Errorf("%s: budget 0 allocs/op, got %.1f", c.name, allocs)
+		}
+	}
+	other := set.New(ints(n, n/2)...)
+	for _, c := range []struct {
+		name string
+		f    func() set.Set[int]
+	}{
+		{"Set.Union", func() set.Set[int] { return s.Union(other) }},
+		{"Set.Intersection", func() set.Set[int] {
+			return s.Intersection(other)
+		}},
+		{"Set.Difference", func() set.Set[int] {
+			return s.Difference(other)
+		}},
+		{"Set.SymmetricDifference", func() set.Set[int] {
+			return s.SymmetricDifference(other)
+		}},
+	} {
+		result := c.f()
+		budget := testing.AllocsPerRun(20, func() { result.Clone() })
+		allocs := testing.AllocsPerRun(20, func() { c.f() })
+		if allocs > budget {
+			t.Errorf("%s: budget %.1f allocs/op, got %.1f", c.name, budget,
+				allocs)
+		}
+	}
+}
+
+func BenchmarkSortedUnion(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(name("int", n), func(b *testing.B) {
+			x := set.NewSorted(ints(n, 0)...)
+			y := set.NewSorted(ints(n, n/2)...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				x.Union(&y)
+			}
+		})
+	}
+}
+
+// BenchmarkConcurrentContains compares the SyncSet, whose reads load an
+// atomically swapped copy-on-write map, the ReadMostlySet, and a Set
+// guarded by an RWMutex (as SyncSet once was) for parallel Contains
+// calls, with no writes and with a delete and re-add per hundred calls.
+func BenchmarkConcurrentContains(b *testing.B) {
+	for _, n := range sizes {
+		for _, writes := range []bool{false, true} {
+			suffix := fmt.Sprintf("n=%d/writes=%t", n, writes)
+			b.Run("set=sync/"+suffix, func(b *testing.B) {
+				benchConcurrentContains(b, set.NewSync(ints(n, 0)...),
+					writes)
+			})
+			b.Run("set=readmostly/"+suffix, func(b *testing.B) {
+				benchConcurrentContains(b,
+					set.NewReadMostly(ints(n, 0)...), writes)
+			})
+			b.Run("set=rwmutex/"+suffix, func(b *testing.B) {
+				benchConcurrentContains(b,
+					&rwMutexSet{set: set.New(ints(n, 0)...)}, writes)
+			})
+		}
+	}
+}
+
+// concurrentContainer is the part of [set.ConcurrentSet] that
+// BenchmarkConcurrentContains uses.
+type concurrentContainer interface {
+	Len() int
+	Add(elements ...int)
+	Delete(elements ...int)
+	Contains(element int) bool
+}
+
+func benchConcurrentContains(b *testing.B, s concurrentContainer,
+	writes bool) {
+	n := s.Len()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if writes && i%100 == 0 {
+				s.Delete(i % n)
+				s.Add(i % n)
+			} else {
+				s.Contains(i % n)
+			}
+		}
+	})
+}
+
+// rwMutexSet is a Set guarded by a sync.RWMutex: the baseline that
+// BenchmarkConcurrentContains compares the lock-free reads against.
+type rwMutexSet struct {
+	mutex sync.RWMutex
+	set   set.Set[int]
+}
+
+func (me *rwMutexSet) Len() int {
+	me.mutex.RLock()
+	defer me.mutex.RUnlock()
+	return me.set.Len()
+}
+
+func (me *rwMutexSet) Add(elements ...int) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	me.set.Add(elements...)
+}
+
+func (me *rwMutexSet) Delete(elements ...int) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	me.set.Delete(elements...)
+}
+
+func (me *rwMutexSet) Contains(element int) bool {
+	me.mutex.RLock()
+	defer me.mutex.RUnlock()
+	return me.set.Contains(element)
+}
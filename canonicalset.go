@@ -0,0 +1,73 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "iter"
+
+// CanonicalSet is a Set which passes every element through a
+// canonicalization function (e.g., to trim space, fold case, or clamp
+// precision) before storing or looking it up. So Contains, Delete, etc.,
+// transparently work on the canonical form.
+type CanonicalSet[E comparable] struct {
+	set   Set[E]
+	canon func(E) E
+}
+
+// NewCanonical returns a new CanonicalSet which uses the given
+// canonicalization function and contains the canonical forms of the given
+// elements (if any).
+func NewCanonical[E comparable](canon func(E) E,
+	elements ...E) CanonicalSet[E] {
+	set := CanonicalSet[E]{set: New[E](), canon: canon}
+	set.Add(elements...)
+	return set
+}
+
+// Canonical returns the canonical form of the given element.
+func (me *CanonicalSet[E]) Canonical(element E) E {
+	return me.canon(element)
+}
+
+// Add adds the canonical forms of the given element(s) to the
+// CanonicalSet.
+func (me *CanonicalSet[E]) Add(elements ...E) {
+	for _, element := range elements {
+		me.set.set[me.canon(element)] = struct{}{}
+	}
+}
+
+// Delete deletes the canonical forms of the given element(s) from the
+// CanonicalSet.
+func (me *CanonicalSet[E]) Delete(elements ...E) {
+	for _, element := range elements {
+		me.set.Delete(me.canon(element))
+	}
+}
+
+// Clear deletes all the elements in the CanonicalSet.
+func (me *CanonicalSet[E]) Clear() { me.set.Clear() }
+
+// Len returns the number of elements in the CanonicalSet.
+func (me *CanonicalSet[E]) Len() int { return me.set.Len() }
+
+// IsEmpty returns true if there are no elements in the CanonicalSet;
+// otherwise returns false.
+func (me *CanonicalSet[E]) IsEmpty() bool { return me.set.IsEmpty() }
+
+// Contains returns true if element's canonical form is in the
+// CanonicalSet; otherwise returns false.
+func (me *CanonicalSet[E]) Contains(element E) bool {
+	return me.set.Contains(me.canon(element))
+}
+
+// All returns an iterator over the canonical elements, e.g.,
+// for element := range aset.All() ...
+func (me *CanonicalSet[E]) All() iter.Seq[E] { return me.set.All() }
+
+// ToSet returns a copy of this CanonicalSet's (canonical) elements as a
+// plain Set.
+func (me *CanonicalSet[E]) ToSet() Set[E] { return me.set.Clone() }
+
+// String returns a human readable string representation of the
+// CanonicalSet.
+func (me *CanonicalSet[E]) String() string { return me.set.String() }
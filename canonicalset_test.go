@@ -0,0 +1,39 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalSet(t *testing.T) {
+	canon := func(s string) string {
+		return strings.ToLower(strings.TrimSpace(s))
+	}
+	s := NewCanonical(canon, " Alpha", "BETA ", "alpha")
+	check(sortedStr(s.ToSet()), s.Len(), "{\"alpha\" \"beta\"}", 2, t)
+	if !s.Contains("  ALPHA  ") {
+		t.Error("expected canonical match for ALPHA")
+	}
+	if s.Canonical(" Gamma ") != "gamma" {
+		t.Errorf("expected gamma, got %q", s.Canonical(" Gamma "))
+	}
+	s.Add("Gamma")
+	s.Delete(" Beta")
+	check(sortedStr(s.ToSet()), s.Len(), "{\"alpha\" \"gamma\"}", 2, t)
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Error("unexpected nonempty")
+	}
+}
+
+func TestCanonicalSetFloats(t *testing.T) {
+	s := NewCanonical(func(x float64) float64 {
+		return math.Round(x*100) / 100
+	}, 1.234, 1.2349, 2.5)
+	check(sortedStr(s.ToSet()), s.Len(), "{1.23 2.5}", 2, t)
+	if !s.Contains(2.501) {
+		t.Error("expected clamped match for 2.501")
+	}
+}
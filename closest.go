@@ -0,0 +1,156 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+// ClosestMatch returns the element of the given string set nearest to s by
+// Levenshtein (edit) distance, counted in runes, and true, e.g., for "did
+// you mean …?" suggestions; or "" and false if no element is within
+// maxDist edits (a negative maxDist means no limit, so there is always a
+// match unless the set is empty). Ties go to the lexically smallest
+// element. This scans every element; to look up many strings in a large
+// set use a [BKTree].
+func ClosestMatch[E ~string](set Interface[E], s string,
+	maxDist int) (E, bool) {
+	target := []rune(s)
+	var best E
+	bestDist := -1
+	for element := range set.All() {
+		limit := maxDist
+		if bestDist != -1 {
+			limit = bestDist
+		}
+		if d := levenshtein(target, []rune(string(element)),
+			limit); d != -1 && (bestDist == -1 || d < bestDist ||
+			(d == bestDist && element < best)) {
+			best, bestDist = element, d
+		}
+	}
+	return best, bestDist != -1
+}
+
+// BKTree is an immutable index of a string set's elements (a
+// Burkhard-Keller tree) which finds close matches by edit distance
+// without comparing against every element.
+type BKTree[E ~string] struct {
+	root *bkNode[E]
+	n    int
+}
+
+type bkNode[E ~string] struct {
+	element  E
+	runes    []rune
+	children map[int]*bkNode[E] // keyed by distance from this node
+}
+
+// NewBKTree returns a new BKTree indexing the given string set's elements.
+// Later changes to the set don't affect the BKTree.
+func NewBKTree[E ~string](set Interface[E]) *BKTree[E] {
+	tree := &BKTree[E]{}
+	for element := range set.All() {
+		tree.add(element)
+	}
+	return tree
+}
+
+// Len returns the number of elements in the BKTree.
+func (me *BKTree[E]) Len() int { return me.n }
+
+// ClosestMatch returns the indexed element nearest to s by edit distance
+// and true; or "" and false if no element is within maxDist edits (no
+// limit if maxDist is negative). It returns the same result as the
+// package-level [ClosestMatch] would for the indexed set.
+func (me *BKTree[E]) ClosestMatch(s string, maxDist int) (E, bool) {
+	var best E
+	bestDist := -1
+	if me.root == nil {
+		return best, false
+	}
+	target := []rune(s)
+	pending := []*bkNode[E]{me.root}
+	for len(pending) > 0 {
+		node := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		d := levenshtein(target, node.runes, -1)
+		if (d <= maxDist || maxDist < 0) && (bestDist == -1 || d < bestDist ||
+			(d == bestDist && node.element < best)) {
+			best, bestDist = node.element, d
+		}
+		limit := maxDist
+		if bestDist != -1 { // always the case if there is no maxDist
+			limit = bestDist
+		}
+		// By the triangle inequality only children at distance
+		// d±limit from this node can be within limit of s.
+		for dist, child := range node.children {
+			if dist >= d-limit && dist <= d+limit {
+				pending = append(pending, child)
+			}
+		}
+	}
+	return best, bestDist != -1
+}
+
+func (me *BKTree[E]) add(element E) {
+	node := &bkNode[E]{element: element, runes: []rune(string(element))}
+	if me.root == nil {
+		me.root = node
+		me.n++
+		return
+	}
+	for parent := me.root; ; {
+		d := levenshtein(node.runes, parent.runes, -1)
+		if d == 0 {
+			return // duplicate
+		}
+		child, ok := parent.children[d]
+		if !ok {
+			if parent.children == nil {
+				parent.children = make(map[int]*bkNode[E])
+			}
+			parent.children[d] = node
+			me.n++
+			return
+		}
+		parent = child
+	}
+}
+
+// levenshtein returns the edit distance between a and b, or -1 if limit
+// is >= 0 and the distance exceeds it.
+func levenshtein(a, b []rune, limit int) int {
+	if limit >= 0 && abs(len(a)-len(b)) > limit {
+		return -1
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i, ra := range a {
+		curr[0] = i + 1
+		rowMin := curr[0]
+		for j, rb := range b {
+			cost := 1
+			if ra == rb {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+			rowMin = min(rowMin, curr[j+1])
+		}
+		if limit >= 0 && rowMin > limit {
+			return -1
+		}
+		prev, curr = curr, prev
+	}
+	if d := prev[len(b)]; limit < 0 || d <= limit {
+		return d
+	}
+	return -1
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,70 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLevenshtein(t *testing.T) {
+	for _, c := range []struct {
+		a, b  string
+		limit int
+		exp   int
+	}{{"kitten", "sitting", -1, 3}, {"", "abc", -1, 3}, {"héllo", "hello",
+		-1, 1}, {"kitten", "sitting", 2, -1}, {"same", "same", 0, 0}} {
+		if d := levenshtein([]rune(c.a), []rune(c.b), c.limit); d != c.exp {
+			t.Errorf("%q %q %d: expected %d, got %d", c.a, c.b, c.limit,
+				c.exp, d)
+		}
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	commands := New("commit", "checkout", "cherry-pick", "clone", "push",
+		"pull")
+	tree := NewBKTree(&commands)
+	if tree.Len() != commands.Len() {
+		t.Errorf("expected %d, got %d", commands.Len(), tree.Len())
+	}
+	for _, c := range []struct {
+		s       string
+		maxDist int
+		exp     string
+		ok      bool
+	}{{"comit", 2, "commit", true}, {"chekout", 1, "checkout", true},
+		{"pusl", 1, "pull", true}, // ties with push; pull is smaller
+		{"xyzzy", 2, "", false}, {"clone", 0, "clone", true},
+		{"xyzzy", -1, "clone", true}} {
+		m, ok := ClosestMatch(&commands, c.s, c.maxDist)
+		if m != c.exp || ok != c.ok {
+			t.Errorf("%q: expected %q %t, got %q %t", c.s, c.exp, c.ok, m,
+				ok)
+		}
+		m, ok = tree.ClosestMatch(c.s, c.maxDist)
+		if m != c.exp || ok != c.ok {
+			t.Errorf("tree %q: expected %q %t, got %q %t", c.s, c.exp,
+				c.ok, m, ok)
+		}
+	}
+}
+
+func TestBKTreeAgreesWithScan(t *testing.T) {
+	words := New[string]()
+	for i := range 300 {
+		words.Add(fmt.Sprintf("w%x", i*7919))
+	}
+	tree := NewBKTree(&words)
+	for i := range 100 {
+		s := fmt.Sprintf("w%x", i*31)
+		a, aok := ClosestMatch(&words, s, 2)
+		b, bok := tree.ClosestMatch(s, 2)
+		if a != b || aok != bok {
+			t.Errorf("%q: scan %q %t, tree %q %t", s, a, aok, b, bok)
+		}
+	}
+	var empty BKTree[string]
+	if _, ok := empty.ClosestMatch("x", 5); ok {
+		t.Error("unexpected match in empty tree")
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sync"
+)
+
+// Codec is the interface used by the persistence and serialization
+// features to convert elements to and from bytes. Built-in codecs are
+// provided by [IntCodec], [StringCodec], and [BinaryCodec]; and
+// [LookupCodec] finds a registered or built-in codec for a type.
+// Encode returns an error as well as the bytes since some element types
+// (e.g., those implementing encoding.BinaryMarshaler) can fail to encode.
+type Codec[E comparable] interface {
+	Encode(element E) ([]byte, error)
+	Decode(raw []byte) (E, error)
+}
+
+type intCodec[E Integer] struct{}
+
+// IntCodec returns a Codec that encodes integers as (zig-zag for signed)
+// varints.
+func IntCodec[E Integer]() Codec[E] { return intCodec[E]{} }
+
+func (intCodec[E]) Encode(element E) ([]byte, error) {
+	if isSigned[E]() {
+		return binary.AppendVarint(nil, int64(element)), nil
+	}
+	return binary.AppendUvarint(nil, uint64(element)), nil
+}
+
+func (intCodec[E]) Decode(raw []byte) (E, error) {
+	var n int
+	var element E
+	if isSigned[E]() {
+		var i int64
+		i, n = binary.Varint(raw)
+		element = E(i)
+		if int64(element) != i { // out of range for E
+			n = 0
+		}
+	} else {
+		var u uint64
+		u, n = binary.Uvarint(raw)
+		element = E(u)
+		if uint64(element) != u {
+			n = 0
+		}
+	}
+	if n <= 0 || n != len(raw) {
+		return element, fmt.Errorf("invalid %T varint % x", element, raw)
+	}
+	return element, nil
+}
+
+func isSigned[E Integer]() bool {
+	var zero E
+	return zero-1 < zero
+}
+
+type stringCodec[E ~string] struct{}
+
+// StringCodec returns a Codec that encodes strings as their raw bytes.
+func StringCodec[E ~string]() Codec[E] { return stringCodec[E]{} }
+
+func (stringCodec[E]) Encode(element E) ([]byte, error) {
+	return []byte(element), nil
+}
+
+func (stringCodec[E]) Decode(raw []byte) (E, error) { return E(raw), nil }
+
+type binaryCodec[E interface {
+	comparable
+	encoding.BinaryMarshaler
+}, P interface {
+	*E
+	encoding.BinaryUnmarshaler
+}] struct{}
+
+// BinaryCodec returns a Codec for types that implement
+// encoding.BinaryMarshaler and whose pointers implement
+// encoding.BinaryUnmarshaler, e.g., BinaryCodec[time.Time]().
+func BinaryCodec[E interface {
+	comparable
+	encoding.BinaryMarshaler
+}, P interface {
+	*E
+	encoding.BinaryUnmarshaler
+}]() Codec[E] {
+	return binaryCodec[E, P]{}
+}
+
+func (binaryCodec[E, P]) Encode(element E) ([]byte, error) {
+	return element.MarshalBinary()
+}
+
+func (binaryCodec[E, P]) Decode(raw []byte) (E, error) {
+	var element E
+	err := P(&element).UnmarshalBinary(raw)
+	return element, err
+}
+
+var codecRegistry sync.Map // reflect.Type → Codec[E]
+
+// RegisterCodec registers the codec as the one [LookupCodec] returns for
+// element type E, replacing any previously registered one.
+func RegisterCodec[E comparable](codec Codec[E]) {
+	codecRegistry.Store(reflect.TypeFor[E](), codec)
+}
+
+// LookupCodec returns the Codec registered for element type E (see
+// [RegisterCodec]) and true. If none is registered, a built-in codec is
+// returned (with true) for types whose underlying type is an integer or a
+// string, and for types that support encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler. Otherwise returns nil and false.
+func LookupCodec[E comparable]() (Codec[E], bool) {
+	kind := reflect.TypeFor[E]()
+	if codec, ok := codecRegistry.Load(kind); ok {
+		return codec.(Codec[E]), true
+	}
+	marshaler := reflect.TypeFor[encoding.BinaryMarshaler]()
+	unmarshaler := reflect.TypeFor[encoding.BinaryUnmarshaler]()
+	switch {
+	case kind.Implements(marshaler) &&
+		reflect.PointerTo(kind).Implements(unmarshaler):
+		return reflectBinaryCodec[E]{}, true
+	case kind.Kind() >= reflect.Int && kind.Kind() <= reflect.Uintptr,
+		kind.Kind() == reflect.String:
+		return reflectCodec[E]{}, true
+	}
+	return nil, false
+}
+
+// reflectCodec handles named integer and string types for LookupCodec.
+type reflectCodec[E comparable] struct{}
+
+func (reflectCodec[E]) Encode(element E) ([]byte, error) {
+	value := reflect.ValueOf(element)
+	switch {
+	case value.CanInt():
+		return binary.AppendVarint(nil, value.Int()), nil
+	case value.CanUint():
+		return binary.AppendUvarint(nil, value.Uint()), nil
+	}
+	return []byte(value.String()), nil
+}
+
+func (reflectCodec[E]) Decode(raw []byte) (E, error) {
+	var element E
+	value := reflect.ValueOf(&element).Elem()
+	var n int
+	switch {
+	case value.CanInt():
+		var i int64
+		i, n = binary.Varint(raw)
+		value.SetInt(i)
+		if value.Int() != i {
+			n = 0
+		}
+	case value.CanUint():
+		var u uint64
+		u, n = binary.Uvarint(raw)
+		value.SetUint(u)
+		if value.Uint() != u {
+			n = 0
+		}
+	default:
+		value.SetString(string(raw))
+		return element, nil
+	}
+	if n <= 0 || n != len(raw) {
+		return element, fmt.Errorf("invalid %T varint % x", element, raw)
+	}
+	return element, nil
+}
+
+// reflectBinaryCodec handles encoding.Binary(Un)Marshaler types for
+// LookupCodec.
+type reflectBinaryCodec[E comparable] struct{}
+
+func (reflectBinaryCodec[E]) Encode(element E) ([]byte, error) {
+	return any(element).(encoding.BinaryMarshaler).MarshalBinary()
+}
+
+func (reflectBinaryCodec[E]) Decode(raw []byte) (E, error) {
+	var element E
+	err := any(&element).(encoding.BinaryUnmarshaler).UnmarshalBinary(raw)
+	return element, err
+}
+
+// EncodeTo writes the Set's elements (in no particular order) to w using
+// the given codec. The format is the number of elements followed by each
+// element's length and bytes, all lengths being uvarints.
+// See also [DecodeFrom].
+func (me *Set[E]) EncodeTo(w io.Writer, codec Codec[E]) error {
+	out := bufio.NewWriter(w)
+	buf := binary.AppendUvarint(nil, uint64(len(me.set)))
+	if _, err := out.Write(buf); err != nil {
+		return err
+	}
+	for element := range me.set {
+		raw, err := codec.Encode(element)
+		if err != nil {
+			return err
+		}
+		buf = binary.AppendUvarint(buf[:0], uint64(len(raw)))
+		if _, err := out.Write(buf); err != nil {
+			return err
+		}
+		if _, err := out.Write(raw); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// DecodeFrom returns a new Set containing the elements read from r using
+// the given codec. The data must be in the format written by
+// [Set.EncodeTo]. If r is empty, io.EOF is returned; other failures are
+// returned as a [*ParseError].
+func DecodeFrom[E comparable](r io.Reader, codec Codec[E]) (Set[E], error) {
+	in, ok := r.(byteReader)
+	if !ok {
+		in = bufio.NewReader(r)
+	}
+	counter := &countingReader{in: in}
+	count, err := binary.ReadUvarint(counter)
+	if err != nil {
+		if counter.offset == 0 {
+			return New[E](), err
+		}
+		return New[E](), &ParseError{counter.offset, unexpectedEOF(err)}
+	}
+	set := Set[E]{set: make(map[E]struct{}, min(count, 1<<16))}
+	var raw bytes.Buffer
+	for range count {
+		offset := counter.offset
+		size, err := binary.ReadUvarint(counter)
+		if err != nil {
+			return set, &ParseError{offset, unexpectedEOF(err)}
+		}
+		// Don't trust size for allocating: the data may be corrupt.
+		raw.Reset()
+		if _, err := io.CopyN(&raw, counter, int64(min(size,
+			math.MaxInt64))); err != nil {
+			return set, &ParseError{offset, unexpectedEOF(err)}
+		}
+		element, err := codec.Decode(raw.Bytes())
+		if err != nil {
+			return set, &ParseError{offset, err}
+		}
+		set.set[element] = struct{}{}
+	}
+	return set, nil
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// countingReader counts the bytes read; DecodeFrom uses this to report
+// error offsets.
+type countingReader struct {
+	in     byteReader
+	offset int64
+}
+
+func (me *countingReader) Read(p []byte) (int, error) {
+	n, err := me.in.Read(p)
+	me.offset += int64(n)
+	return n, err
+}
+
+func (me *countingReader) ReadByte() (byte, error) {
+	b, err := me.in.ReadByte()
+	if err == nil {
+		me.offset++
+	}
+	return b, err
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
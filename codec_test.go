@@ -0,0 +1,152 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+type colour uint8
+
+type label string
+
+func TestIntCodec(t *testing.T) {
+	codec := IntCodec[int64]()
+	for _, x := range []int64{0, 1, -1, 1 << 40, -(1 << 40)} {
+		raw, err := codec.Encode(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if y, err := codec.Decode(raw); err != nil || x != y {
+			t.Errorf("expected %d, got %d %v", x, y, err)
+		}
+	}
+	ucodec := IntCodec[colour]()
+	raw, _ := ucodec.Encode(200)
+	if c, err := ucodec.Decode(raw); err != nil || c != 200 {
+		t.Errorf("expected 200, got %d %v", c, err)
+	}
+	if _, err := codec.Decode([]byte{0x80}); err == nil {
+		t.Error("expected invalid varint error")
+	}
+	raw, _ = IntCodec[int]().Encode(300)
+	if x, err := IntCodec[int8]().Decode(raw); err == nil {
+		t.Errorf("expected overflow error, got %d", x)
+	}
+	raw, _ = IntCodec[uint]().Encode(256)
+	if c, err := ucodec.Decode(raw); err == nil {
+		t.Errorf("expected overflow error, got %d", c)
+	}
+}
+
+func TestStringAndBinaryCodecs(t *testing.T) {
+	scodec := StringCodec[label]()
+	raw, _ := scodec.Encode("hello")
+	if s, err := scodec.Decode(raw); err != nil || s != "hello" {
+		t.Errorf("expected hello, got %q %v", s, err)
+	}
+	tcodec := BinaryCodec[time.Time]()
+	when := time.Date(2025, 6, 7, 8, 9, 10, 11, time.UTC)
+	raw, err := tcodec.Encode(when)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, err := tcodec.Decode(raw); err != nil || !w.Equal(when) {
+		t.Errorf("expected %v, got %v %v", when, w, err)
+	}
+}
+
+func TestLookupCodec(t *testing.T) {
+	type point struct{ x, y int }
+	if _, ok := LookupCodec[point](); ok {
+		t.Error("expected no codec for point")
+	}
+	for _, c := range []label{"", "x", "a label"} {
+		codec, ok := LookupCodec[label]()
+		if !ok {
+			t.Fatal("expected built-in codec for label")
+		}
+		raw, _ := codec.Encode(c)
+		if d, err := codec.Decode(raw); err != nil || c != d {
+			t.Errorf("expected %q, got %q %v", c, d, err)
+		}
+	}
+	ccodec, _ := LookupCodec[colour]()
+	raw, _ := ccodec.Encode(255)
+	if c, err := ccodec.Decode(raw); err != nil || c != 255 {
+		t.Errorf("expected 255, got %d %v", c, err)
+	}
+	raw, _ = IntCodec[int]().Encode(256)
+	if _, err := ccodec.Decode(raw); err == nil {
+		t.Error("expected overflow error")
+	}
+	tcodec, ok := LookupCodec[time.Time]()
+	if !ok {
+		t.Fatal("expected built-in codec for time.Time")
+	}
+	when := time.Date(2025, 6, 7, 8, 9, 10, 11, time.UTC)
+	raw, _ = tcodec.Encode(when)
+	if w, err := tcodec.Decode(raw); err != nil || !w.Equal(when) {
+		t.Errorf("expected %v, got %v %v", when, w, err)
+	}
+	RegisterCodec(pointCodec{})
+	pcodec, ok := LookupCodec[point2]()
+	if !ok || pcodec != (pointCodec{}) {
+		t.Errorf("expected registered codec, got %v %t", pcodec, ok)
+	}
+}
+
+type point2 struct{ x, y int8 }
+
+type pointCodec struct{}
+
+func (pointCodec) Encode(p point2) ([]byte, error) {
+	return []byte{byte(p.x), byte(p.y)}, nil
+}
+
+func (pointCodec) Decode(raw []byte) (point2, error) {
+	if len(raw) != 2 {
+		return point2{}, errors.New("invalid point")
+	}
+	return point2{int8(raw[0]), int8(raw[1])}, nil
+}
+
+func TestEncodeDecode(t *testing.T) {
+	s := New[int64](-5, 0, 7, 1<<33)
+	var buf bytes.Buffer
+	if err := s.EncodeTo(&buf, IntCodec[int64]()); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.Bytes()
+	u, err := DecodeFrom(bytes.NewReader(raw), IntCodec[int64]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.Equal(s) {
+		t.Errorf("expected %v, got %v", s, u)
+	}
+	_, err = DecodeFrom(bytes.NewReader(raw[:len(raw)-1]),
+		IntCodec[int64]())
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected unexpected EOF, got %v", err)
+	}
+	p := New(point2{1, 2}, point2{-3, 4})
+	buf.Reset()
+	if err := p.EncodeTo(&buf, pointCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	q, err := DecodeFrom[point2](&buf, pointCodec{})
+	if err != nil || !q.Equal(p) {
+		t.Errorf("expected %v, got %v %v", p, q, err)
+	}
+	e := New[string]()
+	buf.Reset()
+	_ = e.EncodeTo(&buf, StringCodec[string]())
+	f, err := DecodeFrom(&buf, StringCodec[string]())
+	if err != nil || !f.IsEmpty() {
+		t.Errorf("expected empty set, got %v %v", f, err)
+	}
+}
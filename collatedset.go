@@ -0,0 +1,117 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"maps"
+)
+
+// CollatedSet is a set of strings in which elements are equal if they
+// have the same collation key, so Contains, Delete, and Equal respect
+// locale-specific case folding, accents, normalization, etc. Unlike a
+// [CanonicalSet] it keeps each element as it was first added, which is
+// what users expect to see. The key function is typically backed by a
+// collator, e.g., using golang.org/x/text/collate:
+//
+//	coll := collate.New(language.Turkish, collate.IgnoreCase)
+//	var buf collate.Buffer
+//	key := func(s string) string {
+//		defer buf.Reset()
+//		return string(coll.KeyFromString(&buf, s))
+//	}
+//	tags := set.NewCollated(key, "Istanbul", "ıstanbul")
+//
+// The set package itself has no dependency on x/text.
+type CollatedSet[E ~string] struct {
+	set map[string]E // key → element as first added
+	key func(string) string
+}
+
+// NewCollated returns a new CollatedSet which uses the given collation
+// key function and contains the given elements (if any).
+func NewCollated[E ~string](key func(string) string,
+	elements ...E) CollatedSet[E] {
+	set := CollatedSet[E]{set: make(map[string]E, len(elements)), key: key}
+	set.Add(elements...)
+	return set
+}
+
+// Add adds the given element(s) to the CollatedSet, except for those that
+// collate equal to an element already present.
+func (me *CollatedSet[E]) Add(elements ...E) {
+	for _, element := range elements {
+		key := me.key(string(element))
+		if _, ok := me.set[key]; !ok {
+			me.set[key] = element
+		}
+	}
+}
+
+// Delete deletes the elements that collate equal to the given element(s)
+// from the CollatedSet.
+func (me *CollatedSet[E]) Delete(elements ...E) {
+	for _, element := range elements {
+		delete(me.set, me.key(string(element)))
+	}
+}
+
+// Clear deletes all the elements in the CollatedSet.
+func (me *CollatedSet[E]) Clear() { clear(me.set) }
+
+// Len returns the number of elements in the CollatedSet.
+func (me *CollatedSet[E]) Len() int { return len(me.set) }
+
+// IsEmpty returns true if there are no elements in the CollatedSet;
+// otherwise returns false.
+func (me *CollatedSet[E]) IsEmpty() bool { return len(me.set) == 0 }
+
+// Contains returns true if an element that collates equal to element is
+// in the CollatedSet; otherwise returns false.
+func (me *CollatedSet[E]) Contains(element E) bool {
+	_, ok := me.set[me.key(string(element))]
+	return ok
+}
+
+// Lookup returns the CollatedSet's element (as first added) that collates
+// equal to element and true; or "" and false if there isn't one.
+func (me *CollatedSet[E]) Lookup(element E) (E, bool) {
+	found, ok := me.set[me.key(string(element))]
+	return found, ok
+}
+
+// Equal returns true if this CollatedSet and the other one have elements
+// with the same collation keys; otherwise returns false. Both sets must
+// use the same key function for this to be meaningful.
+func (me *CollatedSet[E]) Equal(other *CollatedSet[E]) bool {
+	if len(me.set) != len(other.set) {
+		return false
+	}
+	for key := range me.set {
+		if _, ok := other.set[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator over the elements (as first added), e.g.,
+// for element := range aset.All() ...
+func (me *CollatedSet[E]) All() iter.Seq[E] { return maps.Values(me.set) }
+
+// ToSet returns this CollatedSet's elements (as first added) as a plain
+// Set.
+func (me *CollatedSet[E]) ToSet() Set[E] {
+	set := Set[E]{set: make(map[E]struct{}, len(me.set))}
+	for _, element := range me.set {
+		set.set[element] = struct{}{}
+	}
+	return set
+}
+
+// String returns a human readable string representation of the
+// CollatedSet.
+func (me *CollatedSet[E]) String() string {
+	set := me.ToSet()
+	return set.String()
+}
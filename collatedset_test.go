@@ -0,0 +1,44 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"strings"
+	"testing"
+)
+
+// foldKey is a stand-in for a collator: it ignores case and the accents
+// this test uses.
+func foldKey(s string) string {
+	return strings.NewReplacer("é", "e", "è", "e").Replace(
+		strings.ToLower(s))
+}
+
+func TestCollatedSet(t *testing.T) {
+	s := NewCollated(foldKey, "Café", "cafe", "Thé", "the")
+	check(sortedStr(s.ToSet()), s.Len(), "{\"Café\" \"Thé\"}", 2, t)
+	if !s.Contains("CAFE") {
+		t.Error("expected CAFE to collate equal to Café")
+	}
+	if x, ok := s.Lookup("thè"); !ok || x != "Thé" {
+		t.Errorf("expected Thé, got %q %t", x, ok)
+	}
+	o := NewCollated(foldKey, "THE", "CAFÉ")
+	if !s.Equal(&o) {
+		t.Error("expected collated equality")
+	}
+	s.Delete("CAFÉ")
+	if s.Contains("café") || s.Len() != 1 || s.Equal(&o) {
+		t.Errorf("unexpected %v", s.String())
+	}
+	n := 0
+	for range s.All() {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("expected 1, got %d", n)
+	}
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Error("unexpected nonempty")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"slices"
+)
+
+// CollectOptions are hints about a sequence's elements and how the
+// collected set will be used, passed to [CollectWithHints].
+type CollectOptions struct {
+	Size   int  // Expected number of elements (0 if unknown)
+	Sorted bool // Elements arrive in ascending order
+	Frozen bool // The collected set won't be modified
+}
+
+// CollectWithHints returns a set containing the elements from the given
+// sequence, choosing and sizing the representation using the given hints.
+// Currently, frozen string sets are returned as a *FrozenStringSet (built
+// without sorting if the elements are sorted), and everything else as a
+// *Set pre-sized for the expected number of elements (with adjacent
+// duplicates skipped cheaply if the elements are sorted). Use a type
+// switch if the concrete type matters. Incorrect hints only affect
+// performance.
+func CollectWithHints[E comparable](seq iter.Seq[E],
+	opts CollectOptions) Interface[E] {
+	if opts.Frozen {
+		if values, ok := any(seq).(iter.Seq[string]); ok {
+			frozen := collectFrozenStrings(values, opts)
+			return any(&frozen).(Interface[E])
+		}
+	}
+	set := Set[E]{set: make(map[E]struct{}, max(0, opts.Size))}
+	if opts.Sorted {
+		var prev E
+		first := true
+		for element := range seq {
+			if first || element != prev {
+				set.set[element] = struct{}{}
+				prev, first = element, false
+			}
+		}
+	} else {
+		for element := range seq {
+			set.set[element] = struct{}{}
+		}
+	}
+	return &set
+}
+
+func collectFrozenStrings(seq iter.Seq[string],
+	opts CollectOptions) FrozenStringSet {
+	elements := slices.AppendSeq(make([]string, 0, max(0, opts.Size)), seq)
+	if !opts.Sorted || !slices.IsSorted(elements) {
+		slices.Sort(elements)
+	}
+	return newFrozenStringSet(slices.Compact(elements))
+}
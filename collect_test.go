@@ -0,0 +1,35 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCollectWithHints(t *testing.T) {
+	ints := []int{1, 1, 2, 3, 3, 3, 7}
+	s := CollectWithHints(slices.Values(ints),
+		CollectOptions{Size: len(ints), Sorted: true})
+	u, ok := s.(*Set[int])
+	if !ok {
+		t.Fatalf("expected *Set[int], got %T", s)
+	}
+	check(sortedStr(*u), u.Len(), "{1 2 3 7}", 4, t)
+	s = CollectWithHints(slices.Values([]int{3, 1, 3, 2}),
+		CollectOptions{Sorted: true}) // wrong hint
+	check(sortedStr(*s.(*Set[int])), s.Len(), "{1 2 3}", 3, t)
+	strs := []string{"b", "a", "c", "a"}
+	f := CollectWithHints(slices.Values(strs), CollectOptions{Frozen: true})
+	z, ok := f.(*FrozenStringSet)
+	if !ok {
+		t.Fatalf("expected *FrozenStringSet, got %T", f)
+	}
+	check(z.String(), z.Len(), `{"a" "b" "c"}`, 3, t)
+	f = CollectWithHints(slices.Values([]string{"a", "b", "b"}),
+		CollectOptions{Frozen: true, Sorted: true, Size: -1})
+	check(f.(*FrozenStringSet).String(), f.Len(), `{"a" "b"}`, 2, t)
+	g := CollectWithHints(slices.Values(strs), CollectOptions{})
+	if _, ok := g.(*Set[string]); !ok || g.Len() != 3 {
+		t.Errorf("expected *Set[string] of 3, got %T", g)
+	}
+}
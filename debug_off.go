@@ -0,0 +1,9 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+//go:build !setdebug
+
+package set
+
+// debug is false (so invariant checks are compiled out) unless built with
+// -tags setdebug.
+const debug = false
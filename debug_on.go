@@ -0,0 +1,10 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+//go:build setdebug
+
+package set
+
+// debug is true when built with -tags setdebug, in which case the
+// package's types check their internal invariants after every mutation and
+// panic with a diagnostic if any is violated.
+const debug = true
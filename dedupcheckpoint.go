@@ -0,0 +1,98 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// DedupCheckpoint configures the persistence of a [StreamDeduper] opened
+// with [OpenStreamDeduper], so that its dedup guarantees survive process
+// restarts.
+type DedupCheckpoint[E comparable] struct {
+	Filename string   // Where the deduper's memory is saved
+	Codec    Codec[E] // How elements are encoded; nil means [LookupCodec]
+	// Interval is the minimum time between automatic checkpoints, which
+	// are made by [StreamDeduper.FirstSeen] when it sees a new element; 0
+	// means only checkpoint when [StreamDeduper.Checkpoint] or
+	// [StreamDeduper.Close] is called.
+	Interval time.Duration
+	// Fsync is whether each checkpoint is synced to stable storage before
+	// it replaces the previous one (and the replacement is synced too);
+	// this is slower but survives power loss as well as process restarts.
+	Fsync bool
+}
+
+// OpenStreamDeduper returns a new StreamDeduper (see [NewStreamDeduper])
+// which is checkpointed to disk as configured, after first loading its
+// memory from the checkpoint file if it exists. Call
+// [StreamDeduper.Close] to make a final checkpoint.
+func OpenStreamDeduper[E comparable](limit int,
+	checkpoint DedupCheckpoint[E]) (*StreamDeduper[E], error) {
+	if checkpoint.Codec == nil {
+		var ok bool
+		if checkpoint.Codec, ok = LookupCodec[E](); !ok {
+			return nil, errors.New("cannot checkpoint: no codec")
+		}
+	}
+	deduper := NewStreamDeduper[E](limit)
+	deduper.checkpoint = &checkpoint
+	deduper.now = time.Now
+	file, err := os.Open(checkpoint.Filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return deduper, err
+	}
+	defer file.Close()
+	in := bufio.NewReader(file)
+	codec := checkpoint.Codec
+	if deduper.previous, err = DecodeFrom(in, codec); err != nil {
+		return deduper, err
+	}
+	if deduper.current, err = DecodeFrom(in, codec); err != nil {
+		return deduper, unexpectedEOF(err)
+	}
+	deduper.saved = deduper.now()
+	return deduper, nil
+}
+
+// Checkpoint saves the StreamDeduper's memory to its checkpoint file now,
+// replacing the file atomically. It returns the error from any failed
+// automatic checkpoint made since the last call, or nil if the deduper
+// wasn't opened with [OpenStreamDeduper].
+func (me *StreamDeduper[E]) Checkpoint() error {
+	if me.checkpoint == nil {
+		return nil
+	}
+	err := writeFileAtomic(me.checkpoint.Filename, me.checkpoint.Fsync,
+		func(w io.Writer) error {
+			err := me.previous.EncodeTo(w, me.checkpoint.Codec)
+			if err != nil {
+				return err
+			}
+			return me.current.EncodeTo(w, me.checkpoint.Codec)
+		})
+	me.saved = me.now()
+	err = errors.Join(me.err, err)
+	me.err = nil
+	return err
+}
+
+// Close makes a final checkpoint (see [StreamDeduper.Checkpoint]).
+func (me *StreamDeduper[E]) Close() error { return me.Checkpoint() }
+
+// maybeCheckpoint makes an automatic checkpoint if one is due, keeping any
+// error for the next call to Checkpoint.
+func (me *StreamDeduper[E]) maybeCheckpoint() {
+	if me.checkpoint != nil && me.checkpoint.Interval > 0 &&
+		me.now().Sub(me.saved) >= me.checkpoint.Interval {
+		me.err = me.Checkpoint()
+	}
+}
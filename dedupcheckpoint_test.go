@@ -0,0 +1,82 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDedupCheckpoint(t *testing.T) {
+	config := DedupCheckpoint[string]{
+		Filename: filepath.Join(t.TempDir(), "dedup.bin")}
+	d, err := OpenStreamDeduper(2, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range []string{"a", "b", "c"} {
+		d.FirstSeen(event)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = OpenStreamDeduper(2, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Len() != 3 || d.FirstSeen("b") || d.FirstSeen("c") {
+		t.Errorf("expected restored memory, got %d", d.Len())
+	}
+	if !d.FirstSeen("z") {
+		t.Error("expected z to be new")
+	}
+}
+
+func TestDedupCheckpointInterval(t *testing.T) {
+	config := DedupCheckpoint[int]{
+		Filename: filepath.Join(t.TempDir(), "dedup.bin"),
+		Codec:    IntCodec[int](), Interval: time.Minute, Fsync: true}
+	d, err := OpenStreamDeduper(0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return clock }
+	d.saved = clock
+	d.FirstSeen(1)
+	if _, err := os.Stat(config.Filename); err == nil {
+		t.Error("expected no checkpoint before the interval")
+	}
+	clock = clock.Add(time.Minute)
+	d.FirstSeen(1) // not new, so no checkpoint
+	d.FirstSeen(2)
+	restored, err := OpenStreamDeduper(0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Len() != 2 {
+		t.Errorf("expected automatic checkpoint of 2, got %d",
+			restored.Len())
+	}
+}
+
+func TestDedupCheckpointErrors(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "bad.bin")
+	os.WriteFile(filename, []byte{5, 1}, 0o644)
+	if _, err := OpenStreamDeduper(0,
+		DedupCheckpoint[string]{Filename: filename}); err == nil {
+		t.Error("expected error for corrupt checkpoint")
+	}
+	d, _ := OpenStreamDeduper(0, DedupCheckpoint[string]{
+		Filename: filepath.Join(dir, "missing", "x.bin"),
+		Interval: time.Nanosecond})
+	d.FirstSeen("x") // the automatic checkpoint fails
+	if err := d.Checkpoint(); err == nil {
+		t.Error("expected checkpoint error")
+	}
+	if err := NewStreamDeduper[int](0).Checkpoint(); err != nil {
+		t.Errorf("expected nil for unpersisted deduper, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"errors"
+	"fmt"
+)
+
+// These are the sentinel errors returned (normally wrapped) by the
+// package's fallible APIs; use errors.Is to check for them.
+var (
+	// ErrElementExists indicates that an element is already present where
+	// it must not be.
+	ErrElementExists = errors.New("element already exists")
+	// ErrNotFound indicates that an element isn't present where it must
+	// be.
+	ErrNotFound = errors.New("element not found")
+	// ErrCapacityExceeded indicates that a set is too big, e.g., for an
+	// encoding's size limits.
+	ErrCapacityExceeded = errors.New("capacity exceeded")
+	// ErrInvalidElement indicates that an element was rejected by a
+	// validator (see [ValidatedSet]).
+	ErrInvalidElement = errors.New("invalid element")
+)
+
+// ParseError is returned when decoding or loading encoded set data fails;
+// use errors.As to access it.
+type ParseError struct {
+	Offset int64 // Byte offset in the data where the problem was found
+	Err    error // The underlying error
+}
+
+func (me *ParseError) Error() string {
+	return fmt.Sprintf("parse error at offset %d: %v", me.Offset, me.Err)
+}
+
+func (me *ParseError) Unwrap() error { return me.Err }
@@ -0,0 +1,52 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	s := New(1, 2, 3)
+	var buf bytes.Buffer
+	_ = s.EncodeTo(&buf, IntCodec[int]())
+	raw := buf.Bytes()
+	_, err := DecodeFrom(bytes.NewReader(raw[:len(raw)-1]), IntCodec[int]())
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Offset != 5 ||
+		!errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected unexpected EOF at offset 5, got %v", err)
+	}
+	bad := []byte{2, 1, 0, 1, 0x80}
+	_, err = DecodeFrom(bytes.NewReader(bad), IntCodec[int]())
+	if !errors.As(err, &perr) || perr.Offset != 3 {
+		t.Errorf("expected parse error at offset 3, got %v", err)
+	}
+	_, err = DecodeFrom(bytes.NewReader(nil), IntCodec[int]())
+	if err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+	_, err = LoadFrozenStringSet([]byte("FSS1"))
+	if !errors.As(err, &perr) || perr.Offset != 0 {
+		t.Errorf("expected parse error at offset 0, got %v", err)
+	}
+	_, err = LoadFrozenStringSetChecked(
+		[]byte("FSS1\x01\x00\x00\x00\x01\x00\x00\x00\x09\x00\x00\x00"))
+	if !errors.As(err, &perr) || perr.Offset != 12 {
+		t.Errorf("expected parse error at offset 12, got %v", err)
+	}
+	if perr.Error() != "parse error at offset 12: invalid frozen string "+
+		"set block offset 9" {
+		t.Errorf("unexpected message %q", perr.Error())
+	}
+}
+
+func TestInvalidElementError(t *testing.T) {
+	s, _ := NewValidated(validEmail)
+	err := s.TryAdd("NOT AN EMAIL")
+	if !errors.Is(err, ErrInvalidElement) || !errors.Is(err, errBadEmail) {
+		t.Errorf("expected ErrInvalidElement and errBadEmail, got %v", err)
+	}
+}
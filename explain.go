@@ -0,0 +1,99 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// Reason says why an element is reported by [ExplainDifference].
+type Reason uint8
+
+// The possible Reasons.
+const (
+	InBoth Reason = iota
+	OnlyInA
+	OnlyInB
+)
+
+func (me Reason) String() string {
+	switch me {
+	case OnlyInA:
+		return "only in a"
+	case OnlyInB:
+		return "only in b"
+	}
+	return "in both"
+}
+
+// ExplainDifference returns an iterator over every element of a and of b
+// (which may be of different set types) with the Reason that says where
+// it is, e.g., for debugging configuration drift:
+//
+//	for element, reason := range set.ExplainDifference(&want, &have) {
+//		if reason != set.InBoth {
+//			fmt.Println(element, reason)
+//		}
+//	}
+//
+// The elements of a come first, then those only in b.
+// See also [DifferenceSummary].
+func ExplainDifference[E comparable](a,
+	b Interface[E]) iter.Seq2[E, Reason] {
+	return func(yield func(E, Reason) bool) {
+		for element := range a.All() {
+			reason := OnlyInA
+			if b.Contains(element) {
+				reason = InBoth
+			}
+			if !yield(element, reason) {
+				return
+			}
+		}
+		for element := range b.All() {
+			if !a.Contains(element) && !yield(element, OnlyInB) {
+				return
+			}
+		}
+	}
+}
+
+// DifferenceSummary returns a compact one-line description of how a and b
+// (which may be of different set types) differ, listing the elements that
+// are only in one of them in sorted order, e.g.,
+//
+//	3 in both; only in a (1): "x"; only in b (2): "y" "z"
+//
+// See also [ExplainDifference].
+func DifferenceSummary[E comparable](a, b Interface[E]) string {
+	format := "%v"
+	var zero E
+	if _, ok := any(zero).(string); ok {
+		format = "%q"
+	}
+	both := 0
+	var onlyA, onlyB []string
+	for element, reason := range ExplainDifference(a, b) {
+		switch reason {
+		case InBoth:
+			both++
+		case OnlyInA:
+			onlyA = append(onlyA, fmt.Sprintf(format, element))
+		case OnlyInB:
+			onlyB = append(onlyB, fmt.Sprintf(format, element))
+		}
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "%d %s", both, InBoth)
+	for i, only := range [][]string{onlyA, onlyB} {
+		if len(only) > 0 {
+			slices.Sort(only)
+			fmt.Fprintf(&out, "; %s (%d): %s", OnlyInA+Reason(i), len(only),
+				strings.Join(only, " "))
+		}
+	}
+	return out.String()
+}
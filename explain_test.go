@@ -0,0 +1,26 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestExplainDifference(t *testing.T) {
+	want := New("a", "b", "c", "x")
+	have := NewFrozen("a", "b", "c", "y", "z")
+	reasons := maps.Collect(ExplainDifference[string](&want, &have))
+	exp := map[string]Reason{"a": InBoth, "b": InBoth, "c": InBoth,
+		"x": OnlyInA, "y": OnlyInB, "z": OnlyInB}
+	if !maps.Equal(reasons, exp) {
+		t.Errorf("expected %v, got %v", exp, reasons)
+	}
+	s := DifferenceSummary[string](&want, &have)
+	if e := `3 in both; only in a (1): "x"; only in b (2): "y" "z"`; s != e {
+		t.Errorf("expected %s, got %s", e, s)
+	}
+	a, b := New(1, 2), New(2, 1)
+	if s := DifferenceSummary[int](&a, &b); s != "2 in both" {
+		t.Errorf("expected 2 in both, got %s", s)
+	}
+}
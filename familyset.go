@@ -0,0 +1,104 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"slices"
+)
+
+// FamilySet is a set of sets. Since a Set isn't comparable it can't be the
+// element type of a Set, so a FamilySet instead keys each member set by its
+// [Set.Digest] and stores it as a [FrozenSet] (so later changes to the Set
+// that was added don't affect the FamilySet). Members whose digests collide
+// are told apart by comparing their elements.
+type FamilySet[E comparable] struct {
+	buckets map[uint64][]FrozenSet[E]
+	n       int
+}
+
+// NewFamily returns a new empty FamilySet; the type must be specified
+// since it can't be inferred.
+func NewFamily[E comparable]() FamilySet[E] {
+	return FamilySet[E]{buckets: make(map[uint64][]FrozenSet[E])}
+}
+
+// Add adds a frozen copy of each of the given set(s) that isn't already a
+// member to the FamilySet. It stops and returns an error if a set's
+// elements aren't stable hashable (see [StableHash]).
+func (me *FamilySet[E]) Add(sets ...Set[E]) error {
+	if me.buckets == nil {
+		me.buckets = make(map[uint64][]FrozenSet[E])
+	}
+	for _, set := range sets {
+		digest, err := set.Digest()
+		if err != nil {
+			return err
+		}
+		if me.find(digest, set) == -1 {
+			me.buckets[digest] = append(me.buckets[digest], set.Freeze())
+			me.n++
+		}
+	}
+	return nil
+}
+
+// Delete deletes the given set(s) from the FamilySet.
+func (me *FamilySet[E]) Delete(sets ...Set[E]) {
+	for _, set := range sets {
+		digest, err := set.Digest()
+		if err != nil {
+			continue // can't be a member
+		}
+		if i := me.find(digest, set); i != -1 {
+			bucket := slices.Delete(me.buckets[digest], i, i+1)
+			if len(bucket) == 0 {
+				delete(me.buckets, digest)
+			} else {
+				me.buckets[digest] = bucket
+			}
+			me.n--
+		}
+	}
+}
+
+// Clear deletes all the sets in the FamilySet.
+func (me *FamilySet[E]) Clear() {
+	clear(me.buckets)
+	me.n = 0
+}
+
+// Len returns the number of sets in the FamilySet.
+func (me *FamilySet[E]) Len() int { return me.n }
+
+// IsEmpty returns true if there are no sets in the FamilySet; otherwise
+// returns false.
+func (me *FamilySet[E]) IsEmpty() bool { return me.n == 0 }
+
+// Contains returns true if a set with the same elements as the given set is
+// in the FamilySet; otherwise returns false.
+func (me *FamilySet[E]) Contains(set Set[E]) bool {
+	digest, err := set.Digest()
+	return err == nil && me.find(digest, set) != -1
+}
+
+// All returns an iterator over the member sets, e.g.,
+// for fset := range family.All() ...
+func (me *FamilySet[E]) All() iter.Seq[FrozenSet[E]] {
+	return func(yield func(FrozenSet[E]) bool) {
+		for _, bucket := range me.buckets {
+			for _, fset := range bucket {
+				if !yield(fset) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// find returns the index of set in the digest's bucket or -1.
+func (me *FamilySet[E]) find(digest uint64, set Set[E]) int {
+	return slices.IndexFunc(me.buckets[digest], func(f FrozenSet[E]) bool {
+		return f.view().Equal(set)
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFamilySet(t *testing.T) {
+	f := NewFamily[int]()
+	a, b := New(1, 2), New(2, 1)
+	if err := f.Add(a, b, New(3), New[int]()); err != nil {
+		t.Fatal(err)
+	}
+	if f.Len() != 3 {
+		t.Errorf("expected 3 members, got %d", f.Len())
+	}
+	a.Add(9) // the family holds a frozen copy
+	if f.Contains(a) || !f.Contains(New(2, 1)) || !f.Contains(New[int]()) {
+		t.Error("unexpected membership")
+	}
+	var sizes []int
+	for fset := range f.All() {
+		sizes = append(sizes, fset.Len())
+	}
+	slices.Sort(sizes)
+	if !slices.Equal(sizes, []int{0, 1, 2}) {
+		t.Errorf("expected [0 1 2], got %v", sizes)
+	}
+	f.Delete(New(1, 2), New(7))
+	if f.Len() != 2 || f.Contains(b) {
+		t.Errorf("expected 2 members without {1 2}, got %d", f.Len())
+	}
+	f.Clear()
+	if !f.IsEmpty() {
+		t.Error("unexpected nonempty")
+	}
+}
+
+func TestFamilySetCollision(t *testing.T) {
+	f := NewFamily[int]()
+	x, y := New(1), New(2)
+	dx, _ := x.Digest()
+	// Force both into one bucket to exercise the equality check.
+	f.buckets[dx] = []FrozenSet[int]{x.Freeze(), y.Freeze()}
+	f.n = 2
+	if f.find(dx, y) != 1 || f.find(dx, New(3)) != -1 {
+		t.Error("expected collisions to be resolved by equality")
+	}
+}
+
+func TestFamilySetUnhashable(t *testing.T) {
+	type pair struct{ a, b int }
+	var f FamilySet[pair]
+	if err := f.Add(New(pair{1, 2})); err == nil {
+		t.Error("expected error for unhashable elements")
+	}
+	if f.Contains(New(pair{1, 2})) {
+		t.Error("unexpected membership")
+	}
+}
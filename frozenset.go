@@ -0,0 +1,49 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "iter"
+
+// FrozenSet is an immutable set. Since it can't change, it is safe to share
+// a FrozenSet between goroutines without locking.
+type FrozenSet[E comparable] struct{ set map[E]struct{} }
+
+// Freeze returns a FrozenSet containing a copy of this Set's elements.
+func (me *Set[E]) Freeze() FrozenSet[E] {
+	return FrozenSet[E]{me.Clone().set}
+}
+
+// NewFrozen returns a new FrozenSet containing the given elements (if any).
+func NewFrozen[E comparable](elements ...E) FrozenSet[E] {
+	return FrozenSet[E]{New(elements...).set}
+}
+
+// Len returns the number of elements in the FrozenSet.
+func (me *FrozenSet[E]) Len() int { return len(me.set) }
+
+// IsEmpty returns true if there are no elements in the FrozenSet;
+// otherwise returns false.
+func (me *FrozenSet[E]) IsEmpty() bool { return len(me.set) == 0 }
+
+// Contains returns true if element is in the FrozenSet; otherwise returns
+// false.
+func (me *FrozenSet[E]) Contains(element E) bool {
+	_, ok := me.set[element]
+	return ok
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+func (me *FrozenSet[E]) All() iter.Seq[E] { return me.view().All() }
+
+// ToSlice returns this FrozenSet's elements as an unsorted slice.
+func (me *FrozenSet[E]) ToSlice() []E { return me.view().ToSlice() }
+
+// Thaw returns a (mutable) Set containing a copy of this FrozenSet's
+// elements.
+func (me *FrozenSet[E]) Thaw() Set[E] { return me.view().Clone() }
+
+// String returns a human readable string representation of the FrozenSet.
+func (me *FrozenSet[E]) String() string { return me.view().String() }
+
+// view returns a Set sharing this FrozenSet's map; it must not be mutated.
+func (me *FrozenSet[E]) view() *Set[E] { return &Set[E]{set: me.set} }
@@ -0,0 +1,35 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFrozenSet(t *testing.T) {
+	s := New(3, 1, 2)
+	f := s.Freeze()
+	s.Add(4)
+	if f.Contains(4) || f.Len() != 3 {
+		t.Errorf("expected frozen copy, got %v", f.String())
+	}
+	u := f.Thaw()
+	check(sortedStr(u), u.Len(), "{1 2 3}", 3, t)
+	u.Add(9)
+	if f.Contains(9) {
+		t.Error("expected thawed copy")
+	}
+	v := sorted(f.ToSlice())
+	check(fmt.Sprintf("%v", v), len(v), "[1 2 3]", 3, t)
+	n := 0
+	for x := range f.All() {
+		n += x
+	}
+	if n != 6 {
+		t.Errorf("expected 6, got %d", n)
+	}
+	e := NewFrozen[string]()
+	if !e.IsEmpty() || e.String() != "{}" {
+		t.Errorf("expected empty, got %s", e.String())
+	}
+}
@@ -0,0 +1,420 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	frontCodingBlockSize = 16
+	frozenStringSetMagic = "FSS1"
+	frozenStringSetHead  = len(frozenStringSetMagic) + 8
+)
+
+// FrozenStringSet is an immutable set of strings stored in sorted order
+// using front coding: the strings are grouped into blocks, and within each
+// block every string after the first is stored as the length of the
+// prefix it shares with its predecessor plus the remaining suffix. For
+// sets of many similar strings (URLs, file paths) this uses a fraction of
+// the memory of a Set[string]. Contains is a binary search over the blocks
+// followed by a scan of at most one block.
+type FrozenStringSet struct {
+	n     int
+	index []byte // little-endian uint32 offset into data of each block
+	data  []byte // the front-coded blocks
+}
+
+// NewFrozenStringSet returns a new FrozenStringSet containing the given
+// strings (if any); duplicates are ignored.
+func NewFrozenStringSet(elements ...string) FrozenStringSet {
+	sorted := slices.Clone(elements)
+	slices.Sort(sorted)
+	return newFrozenStringSet(slices.Compact(sorted))
+}
+
+// NewFrozenStringSetFromSorted returns a new FrozenStringSet containing
+// the given strings, which must be in ascending order (adjacent duplicates
+// are ignored). Since no sorting is needed this is O(n). Returns an error
+// if the strings are out of order.
+func NewFrozenStringSetFromSorted(sorted []string) (FrozenStringSet,
+	error) {
+	return NewFrozenStringSetFromSortedSeq(slices.Values(sorted))
+}
+
+// NewFrozenStringSetFromSortedSeq returns a new FrozenStringSet containing
+// the strings from the given sequence, which must be in ascending order
+// (adjacent duplicates are ignored). The set is built as the sequence is
+// consumed, in O(n). Returns an error if the strings are out of order, or
+// wrapping [ErrCapacityExceeded] if they need more than 4GiB.
+func NewFrozenStringSetFromSortedSeq(seq iter.Seq[string]) (FrozenStringSet,
+	error) {
+	var set FrozenStringSet
+	prev := ""
+	for element := range seq {
+		if set.n > 0 {
+			if element == prev {
+				continue
+			}
+			if element < prev {
+				return FrozenStringSet{}, fmt.Errorf(
+					"unsorted strings: %q follows %q", element, prev)
+			}
+		}
+		if uint64(len(set.data)) > math.MaxUint32 {
+			return FrozenStringSet{}, fmt.Errorf(
+				"frozen string set data exceeds 4GiB: %w",
+				ErrCapacityExceeded)
+		}
+		set.append(prev, element)
+		prev = element
+	}
+	if debug {
+		set.checkInvariants()
+	}
+	return set, nil
+}
+
+// NewFrozenStringSetFrom returns a new FrozenStringSet containing the
+// strings in the given set, which may be of any of the package's set
+// types, e.g., a *Set[string]. If s is itself a *FrozenStringSet it is
+// returned as is since it is immutable.
+// See also [FrozenStringSet.ToSet].
+func NewFrozenStringSetFrom(s Interface[string]) FrozenStringSet {
+	if frozen, ok := s.(*FrozenStringSet); ok {
+		return *frozen
+	}
+	sorted := make([]string, 0, s.Len())
+	for element := range s.All() {
+		sorted = append(sorted, element)
+	}
+	slices.Sort(sorted)
+	return newFrozenStringSet(slices.Compact(sorted))
+}
+
+// newFrozenStringSet requires sorted to be in strictly ascending order.
+func newFrozenStringSet(sorted []string) FrozenStringSet {
+	var set FrozenStringSet
+	prev := ""
+	for _, element := range sorted {
+		set.append(prev, element)
+		prev = element
+	}
+	if debug {
+		set.checkInvariants()
+	}
+	return set
+}
+
+// append adds element, which must be greater than prev, the last string
+// added.
+func (me *FrozenStringSet) append(prev, element string) {
+	shared := 0
+	if me.n%frontCodingBlockSize == 0 {
+		me.index = binary.LittleEndian.AppendUint32(me.index,
+			uint32(len(me.data)))
+	} else {
+		shared = commonPrefixLen(prev, element)
+	}
+	me.data = binary.AppendUvarint(me.data, uint64(shared))
+	me.data = binary.AppendUvarint(me.data, uint64(len(element)-shared))
+	me.data = append(me.data, element[shared:]...)
+	me.n++
+}
+
+func commonPrefixLen[A, B string | []byte](a A, b B) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The FrozenStringSet
+// is written as a single buffer (a header, the block index, and the
+// front-coded blocks) which [LoadFrozenStringSet] can query in place.
+func (me FrozenStringSet) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, frozenStringSetHead+len(me.index)+len(me.data))
+	buf = append(buf, frozenStringSetMagic...)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(me.n))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(me.blocks()))
+	buf = append(buf, me.index...)
+	return append(buf, me.data...), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. The data is
+// copied and fully checked (see [LoadFrozenStringSetChecked]); use
+// [LoadFrozenStringSet] to query data in place instead.
+func (me *FrozenStringSet) UnmarshalBinary(data []byte) error {
+	set, err := LoadFrozenStringSetChecked(slices.Clone(data))
+	if err != nil {
+		return err
+	}
+	*me = set
+	return nil
+}
+
+// LoadFrozenStringSet returns a FrozenStringSet that is queried directly
+// from the given buffer, which must have been produced by
+// [FrozenStringSet.MarshalBinary]. Nothing is parsed or copied, so loading
+// is instant even for huge sets, e.g., from an embedded or memory-mapped
+// file; but the buffer must not be modified while the set is in use. Only
+// the header and the size of the block index are checked (problems are
+// reported as a [*ParseError]), so a corrupted block isn't detected: it
+// can't cause a panic, but queries may give wrong results. Use
+// [LoadFrozenStringSetChecked] for buffers that may be corrupt.
+func LoadFrozenStringSet(buf []byte) (FrozenStringSet, error) {
+	var set FrozenStringSet
+	if len(buf) < frozenStringSetHead ||
+		string(buf[:len(frozenStringSetMagic)]) != frozenStringSetMagic {
+		return set, &ParseError{0,
+			errors.New("invalid frozen string set header")}
+	}
+	pos := len(frozenStringSetMagic)
+	n := uint64(binary.LittleEndian.Uint32(buf[pos:]))
+	blocks := uint64(binary.LittleEndian.Uint32(buf[pos+4:]))
+	if blocks != (n+frontCodingBlockSize-1)/frontCodingBlockSize ||
+		n > math.MaxInt || blocks*4 > uint64(len(buf)-frozenStringSetHead) {
+		return set, &ParseError{int64(pos),
+			errors.New("invalid frozen string set index")}
+	}
+	pos = frozenStringSetHead + int(blocks)*4
+	return FrozenStringSet{n: int(n), index: buf[frozenStringSetHead:pos],
+		data: buf[pos:]}, nil
+}
+
+// LoadFrozenStringSetChecked is like [LoadFrozenStringSet] except that it
+// also checks every block (without allocating per string), so a corrupted
+// buffer is reported as a [*ParseError] rather than giving wrong results
+// later. This takes time proportional to the buffer's size.
+func LoadFrozenStringSetChecked(buf []byte) (FrozenStringSet, error) {
+	set, err := LoadFrozenStringSet(buf)
+	if err == nil {
+		err = set.validate()
+	}
+	if err != nil {
+		return FrozenStringSet{}, err
+	}
+	return set, nil
+}
+
+// validate checks that the block offsets ascend and that every block
+// decodes within its bounds to the expected number of strings in strictly
+// ascending order.
+func (me *FrozenStringSet) validate() error {
+	base := frozenStringSetHead + len(me.index) // data's offset in buf
+	fail := func(pos int, format string, args ...any) error {
+		return &ParseError{int64(pos), fmt.Errorf(
+			"invalid frozen string set "+format, args...)}
+	}
+	prev := -1
+	for i := range me.blocks() {
+		offset := binary.LittleEndian.Uint32(me.index[i*4:])
+		if int64(offset) <= int64(prev) ||
+			uint64(offset) >= uint64(len(me.data)) {
+			return fail(frozenStringSetHead+i*4, "block offset %d", offset)
+		}
+		prev = int(offset)
+	}
+	var prevString, current []byte
+	for i := range me.blocks() {
+		start, end := me.bounds(i)
+		count := 0
+		for pos := start; pos < end; count++ {
+			shared, suffix, next, ok := me.entry(pos, end)
+			if !ok || (count == 0 && shared != 0) ||
+				shared > len(prevString) {
+				return fail(base+pos, "block entry")
+			}
+			current = append(append(current[:0], prevString[:shared]...),
+				suffix...)
+			if (i > 0 || count > 0) &&
+				string(current) <= string(prevString) {
+				return fail(base+pos, "block: strings out of order")
+			}
+			pos = next
+			prevString, current = current, prevString
+		}
+		if want := min(frontCodingBlockSize,
+			me.n-i*frontCodingBlockSize); count != want {
+			return fail(base+start, "block: %d strings; expected %d",
+				count, want)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of strings in the FrozenStringSet.
+func (me *FrozenStringSet) Len() int { return me.n }
+
+// IsEmpty returns true if there are no strings in the FrozenStringSet;
+// otherwise returns false.
+func (me *FrozenStringSet) IsEmpty() bool { return me.n == 0 }
+
+// Contains returns true if element is in the FrozenStringSet; otherwise
+// returns false. It compares the stored bytes in place, so it doesn't
+// allocate.
+func (me *FrozenStringSet) Contains(element string) bool {
+	// Find the first block whose head is > element; element can only be
+	// in the block before it. A head shares no prefix, so its suffix is
+	// the whole string.
+	lo, hi := 0, me.blocks()
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		start, end := me.bounds(mid)
+		_, head, _, _ := me.entry(start, end)
+		if string(head) <= element {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo == 0 {
+		return false
+	}
+	// Scan the block without rebuilding its strings: matched is how long
+	// a prefix the previous string (which is < element) shares with
+	// element, so a string sharing more of the previous one is also <
+	// element, and one sharing less is > element.
+	start, end := me.bounds(lo - 1)
+	matched := 0
+	for pos := start; pos < end; {
+		shared, suffix, next, ok := me.entry(pos, end)
+		if !ok || shared < matched {
+			return false
+		}
+		pos = next
+		if shared > matched {
+			continue
+		}
+		rest := element[matched:]
+		k := commonPrefixLen(suffix, rest)
+		switch {
+		case k == len(suffix) && k == len(rest):
+			return true
+		case k < len(suffix) && (k == len(rest) || suffix[k] > rest[k]):
+			return false // this string is > element
+		}
+		matched += k
+	}
+	return false
+}
+
+// All returns an iterator over the strings in sorted order, e.g.,
+// for element := range aset.All() ...
+func (me *FrozenStringSet) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i := range me.blocks() {
+			for element := range me.block(i) {
+				if !yield(element) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ToSlice returns this FrozenStringSet's strings as a sorted slice.
+func (me *FrozenStringSet) ToSlice() []string {
+	slice := make([]string, 0, me.n)
+	for element := range me.All() {
+		slice = append(slice, element)
+	}
+	return slice
+}
+
+// ToSet returns a new Set containing this FrozenStringSet's strings.
+// See also [NewFrozenStringSetFrom].
+func (me *FrozenStringSet) ToSet() Set[string] {
+	set := Set[string]{set: make(map[string]struct{}, me.n)}
+	for element := range me.All() {
+		set.set[element] = struct{}{}
+	}
+	return set
+}
+
+// String returns a human readable string representation of the
+// FrozenStringSet with its strings in sorted order.
+func (me *FrozenStringSet) String() string {
+	var out strings.Builder
+	out.WriteByte('{')
+	sep := ""
+	for element := range me.All() {
+		out.WriteString(sep)
+		out.WriteString(strconv.Quote(element))
+		sep = " "
+	}
+	out.WriteByte('}')
+	return out.String()
+}
+
+func (me *FrozenStringSet) blocks() int { return len(me.index) / 4 }
+
+// offset returns the offset in data of the i-th block, clamped so that a
+// corrupted index can't lead to out of range slicing.
+func (me *FrozenStringSet) offset(i int) int {
+	return int(min(uint64(binary.LittleEndian.Uint32(me.index[i*4:])),
+		uint64(len(me.data))))
+}
+
+// bounds returns the start and end offsets in data of the i-th block.
+func (me *FrozenStringSet) bounds(i int) (int, int) {
+	end := len(me.data)
+	if i+1 < me.blocks() {
+		end = me.offset(i + 1)
+	}
+	return min(me.offset(i), end), end
+}
+
+// block returns an iterator over the strings in the i-th block.
+func (me *FrozenStringSet) block(i int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		start, end := me.bounds(i)
+		var current []byte
+		for pos := start; pos < end; {
+			var ok bool
+			if current, pos, ok = me.decode(pos, end, current); !ok ||
+				!yield(string(current)) {
+				return
+			}
+		}
+	}
+}
+
+// decode returns the string at data[pos:end] (built by reusing the shared
+// prefix of its predecessor, prev), the position of the string that
+// follows it, and true; or false if the data there is corrupt.
+func (me *FrozenStringSet) decode(pos, end int, prev []byte) ([]byte, int,
+	bool) {
+	shared, suffix, next, ok := me.entry(pos, end)
+	if !ok || shared > len(prev) {
+		return prev, end, false
+	}
+	return append(prev[:shared], suffix...), next, true
+}
+
+// entry returns the shared prefix length and the suffix of the string at
+// data[pos:end], the position of the string that follows it, and true; or
+// false if the data there is corrupt.
+func (me *FrozenStringSet) entry(pos, end int) (int, []byte, int, bool) {
+	shared, n := binary.Uvarint(me.data[pos:end])
+	if n <= 0 || shared > uint64(len(me.data)) {
+		return 0, nil, end, false
+	}
+	pos += n
+	size, n := binary.Uvarint(me.data[pos:end])
+	if n <= 0 || size > uint64(end-pos-n) {
+		return 0, nil, end, false
+	}
+	pos += n
+	return int(shared), me.data[pos : pos+int(size)], pos + int(size), true
+}
@@ -0,0 +1,200 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func paths(n int) []string {
+	paths := make([]string, 0, n)
+	for i := range n {
+		paths = append(paths, fmt.Sprintf("/home/mark/app/golib/%03d/%d.go",
+			i%37, i))
+	}
+	return paths
+}
+
+func TestFrozenStringSet(t *testing.T) {
+	s := NewFrozenStringSet("pear", "apple", "banana", "apple", "")
+	if s.Len() != 4 {
+		t.Errorf("expected 4 strings, got %d", s.Len())
+	}
+	check(s.String(), s.Len(), `{"" "apple" "banana" "pear"}`, 4, t)
+	for _, x := range []string{"", "apple", "banana", "pear"} {
+		if !s.Contains(x) {
+			t.Errorf("expected to contain %q", x)
+		}
+	}
+	for _, x := range []string{"a", "apples", "zebra", "banan"} {
+		if s.Contains(x) {
+			t.Errorf("expected not to contain %q", x)
+		}
+	}
+	e := NewFrozenStringSet()
+	if !e.IsEmpty() || e.Contains("") || e.String() != "{}" {
+		t.Errorf("expected empty, got %s", e.String())
+	}
+}
+
+func TestFrozenStringSetMany(t *testing.T) {
+	elements := paths(1000)
+	s := NewFrozenStringSet(elements...)
+	u := New(elements...)
+	if s.Len() != u.Len() {
+		t.Errorf("expected %d strings, got %d", u.Len(), s.Len())
+	}
+	for _, x := range elements {
+		if !s.Contains(x) {
+			t.Errorf("expected to contain %q", x)
+		}
+		if s.Contains(x + "x") {
+			t.Errorf("expected not to contain %q", x+"x")
+		}
+		for _, y := range []string{x[:len(x)/2], x[:len(x)-1] + "~"} {
+			if s.Contains(y) != u.Contains(y) {
+				t.Errorf("expected Contains(%q) to be %t", y, u.Contains(y))
+			}
+		}
+	}
+	slice := s.ToSlice()
+	if !slices.IsSorted(slice) || !slices.Equal(slice,
+		slices.Sorted(u.All())) {
+		t.Error("expected sorted iteration")
+	}
+	raw := 0
+	for _, x := range elements {
+		raw += len(x)
+	}
+	if len(s.data) >= raw/2 {
+		t.Errorf("expected front coding to halve %d bytes, got %d", raw,
+			len(s.data))
+	}
+	n := 0
+	for range s.All() {
+		n++
+		if n == 20 {
+			break
+		}
+	}
+	if n != 20 {
+		t.Errorf("expected to break out at 20, got %d", n)
+	}
+}
+
+func TestFrozenStringSetLoad(t *testing.T) {
+	elements := paths(500)
+	s := NewFrozenStringSet(elements...)
+	buf, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := LoadFrozenStringSet(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Len() != s.Len() || !slices.Equal(u.ToSlice(), s.ToSlice()) {
+		t.Error("expected loaded set to equal original")
+	}
+	for _, x := range elements[:50] {
+		if !u.Contains(x) {
+			t.Errorf("expected to contain %q", x)
+		}
+	}
+	if &u.data[0] != &buf[len(buf)-len(u.data)] {
+		t.Error("expected loaded set to use the buffer in place")
+	}
+	var v FrozenStringSet
+	if err := v.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	}
+	buf[len(buf)-1] = 'X'
+	if !v.Contains(elements[0]) || !slices.Equal(v.ToSlice(),
+		s.ToSlice()) {
+		t.Error("expected unmarshaled set to be a copy")
+	}
+	e := NewFrozenStringSet()
+	buf, _ = e.MarshalBinary()
+	if e, err = LoadFrozenStringSet(buf); err != nil || !e.IsEmpty() {
+		t.Errorf("expected empty set, got %v %v", e.String(), err)
+	}
+	for _, bad := range [][]byte{nil, []byte("FSS1"),
+		[]byte("XXXX\x01\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00"),
+		[]byte("FSS1\x01\x00\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00")} {
+		if _, err := LoadFrozenStringSet(bad); err == nil {
+			t.Errorf("expected error loading %q", bad)
+		}
+	}
+	// Only the checked load detects a bad block offset.
+	bad := []byte("FSS1\x01\x00\x00\x00\x01\x00\x00\x00\x09\x00\x00\x00")
+	if _, err := LoadFrozenStringSetChecked(bad); err == nil {
+		t.Errorf("expected error loading %q", bad)
+	}
+	if u, err := LoadFrozenStringSet(bad); err != nil ||
+		u.Contains("") || len(u.ToSlice()) != 0 {
+		t.Errorf("expected unchecked load to be harmless, got %v", err)
+	}
+}
+
+func TestFrozenStringSetFromSorted(t *testing.T) {
+	elements := slices.Sorted(slices.Values(paths(300)))
+	elements = append(elements[:10:10], elements[9:]...) // a duplicate
+	s, err := NewFrozenStringSetFromSorted(elements)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := NewFrozenStringSet(elements...)
+	if s.Len() != 300 || !slices.Equal(s.data, u.data) ||
+		!slices.Equal(s.index, u.index) {
+		t.Errorf("expected identical sets, got %d strings", s.Len())
+	}
+	v, err := NewFrozenStringSetFromSortedSeq(slices.Values([]string{"",
+		"", "a"}))
+	check(v.String(), v.Len(), `{"" "a"}`, 2, t)
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = NewFrozenStringSetFromSorted([]string{"a", "c", "b"})
+	if err == nil {
+		t.Error("expected unsorted error")
+	}
+}
+
+func TestFrozenStringSetLoadCorrupt(t *testing.T) {
+	s := NewFrozenStringSet(paths(100)...)
+	raw, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Corrupt each byte of the block index and of the blocks in turn.
+	for i := frozenStringSetHead; i < len(raw); i++ {
+		for _, b := range []byte{0x00, 0x7F, 0xFF} {
+			bad := slices.Clone(raw)
+			if bad[i] == b {
+				continue
+			}
+			bad[i] = b
+			u, err := LoadFrozenStringSet(bad)
+			if err != nil {
+				t.Fatalf("expected unchecked load to succeed, got %v", err)
+			}
+			for x := range u.All() { // mustn't panic
+				u.Contains(x)
+			}
+			if u, err = LoadFrozenStringSetChecked(bad); err != nil {
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("expected *ParseError, got %T", err)
+				}
+				continue
+			}
+			for x := range u.All() {
+				if !u.Contains(x) {
+					t.Fatalf("byte %d: checked set lacks %q", i, x)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"math"
+	"slices"
+)
+
+// Duplicates returns a new Set containing the elements which occur more
+// than once in the given sequence.
+// See also [FirstDuplicate].
+func Duplicates[E comparable](seq iter.Seq[E]) Set[E] {
+	seen := New[E]()
+	duplicates := New[E]()
+	for element := range seq {
+		if _, ok := seen.set[element]; ok {
+			duplicates.set[element] = struct{}{}
+		} else {
+			seen.set[element] = struct{}{}
+		}
+	}
+	return duplicates
+}
+
+// FirstDuplicate returns the first element in the given sequence that
+// occurs earlier in the sequence and true; or the zero value and false if
+// there are no duplicates. It stops consuming the sequence at the first
+// duplicate.
+// See also [Duplicates].
+func FirstDuplicate[E comparable](seq iter.Seq[E]) (E, bool) {
+	seen := New[E]()
+	for element := range seq {
+		if _, ok := seen.set[element]; ok {
+			return element, true
+		}
+		seen.set[element] = struct{}{}
+	}
+	var zero E
+	return zero, false
+}
+
+// PairwiseIntersections returns a matrix m where m[i][j] is the number of
+// elements sets[i] and sets[j] have in common (so m[i][i] is the length of
+// sets[i]). All the intersection sizes are counted in a single pass over
+// the sets' elements rather than by computing each intersection.
+// See also [PairwiseJaccard].
+func PairwiseIntersections[E comparable](sets []Set[E]) [][]int {
+	matrix := make([][]int, len(sets))
+	for i := range matrix {
+		matrix[i] = make([]int, len(sets))
+	}
+	owners := make(map[E][]int)
+	for i, set := range sets {
+		for element := range set.set {
+			owners[element] = append(owners[element], i)
+		}
+	}
+	for _, indexes := range owners {
+		for x, i := range indexes {
+			matrix[i][i]++
+			for _, j := range indexes[x+1:] {
+				matrix[i][j]++
+				matrix[j][i]++
+			}
+		}
+	}
+	return matrix
+}
+
+// PairwiseJaccard returns a matrix m where m[i][j] is the Jaccard
+// similarity of sets[i] and sets[j], i.e., the size of their intersection
+// divided by the size of their union (or 1.0 if both are empty).
+// See also [PairwiseIntersections].
+func PairwiseJaccard[E comparable](sets []Set[E]) [][]float64 {
+	counts := PairwiseIntersections(sets)
+	matrix := make([][]float64, len(sets))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(sets))
+		for j := range matrix[i] {
+			common := counts[i][j]
+			union := counts[i][i] + counts[j][j] - common
+			if union == 0 {
+				matrix[i][j] = 1.0
+			} else {
+				matrix[i][j] = float64(common) / float64(union)
+			}
+		}
+	}
+	return matrix
+}
+
+// AtLeast returns a new Set containing the elements that are in at least k
+// of the given sets, e.g., for majority voting over replicas. If k <= 1 the
+// result is the union of the sets. The elements are counted in a single
+// pass.
+func AtLeast[E comparable](k int, sets ...Set[E]) Set[E] {
+	result := New[E]()
+	if k > len(sets) {
+		return result
+	}
+	counts := make(map[E]int)
+	for _, set := range sets {
+		for element := range set.set {
+			counts[element]++
+			if counts[element] == k || k <= 1 {
+				result.set[element] = struct{}{}
+			}
+		}
+	}
+	return result
+}
+
+// UniqueToOne returns a slice of new Sets, one per given set, each
+// containing the elements that are in that set and in none of the others.
+// (For two sets, the union of the results is their symmetric difference.)
+func UniqueToOne[E comparable](sets ...Set[E]) []Set[E] {
+	const shared = -1
+	owner := make(map[E]int)
+	for i, set := range sets {
+		for element := range set.set {
+			if j, ok := owner[element]; ok && j != i {
+				owner[element] = shared
+			} else {
+				owner[element] = i
+			}
+		}
+	}
+	result := make([]Set[E], len(sets))
+	for i := range result {
+		result[i] = New[E]()
+	}
+	for element, i := range owner {
+		if i != shared {
+			result[i].set[element] = struct{}{}
+		}
+	}
+	return result
+}
+
+// GreedySetCover returns the indexes (in the order chosen) of candidates
+// whose union covers the universe, using the standard greedy approximation:
+// repeatedly choose the candidate that covers the most still-uncovered
+// elements (preferring the lowest index on ties). The result is within a
+// factor of ln(n) of optimal. If the candidates can't cover the universe,
+// the result covers as much of it as they can.
+func GreedySetCover[E comparable](universe Set[E],
+	candidates []Set[E]) []int {
+	uncovered := universe.Clone()
+	chosen := make([]int, 0)
+	used := make([]bool, len(candidates))
+	for !uncovered.IsEmpty() {
+		best, bestCount := -1, 0
+		for i, candidate := range candidates {
+			if used[i] {
+				continue
+			}
+			count := 0
+			for element := range candidate.set {
+				if _, ok := uncovered.set[element]; ok {
+					count++
+				}
+			}
+			if count > bestCount {
+				best, bestCount = i, count
+			}
+		}
+		if best == -1 {
+			break
+		}
+		used[best] = true
+		chosen = append(chosen, best)
+		for element := range candidates[best].set {
+			delete(uncovered.set, element)
+		}
+	}
+	return chosen
+}
+
+// SizeHistogram returns how many of the given sets have sizes in each of
+// the ranges bounded by buckets (which must be in ascending order), e.g.,
+// for capacity analysis of systems holding millions of small sets. The
+// count at index i is of sets with sizes > buckets[i-1] (or >= 0 for
+// i == 0) and <= buckets[i]; the extra count at the end is of sets with
+// sizes > the last bucket. So buckets of [0 1 10] give counts of empty
+// sets, singletons, sets of 2-10 elements, and larger sets.
+// See also [SizeSummary].
+func SizeHistogram[E comparable](sets iter.Seq[Set[E]],
+	buckets []int) []int {
+	counts := make([]int, len(buckets)+1)
+	for set := range sets {
+		i, _ := slices.BinarySearch(buckets, len(set.set))
+		counts[i]++
+	}
+	return counts
+}
+
+// SizeStats are summary statistics of set sizes; see [SizeSummary].
+type SizeStats struct {
+	Count  int     // Number of sets
+	Empty  int     // Number of empty sets
+	Total  int     // Sum of the sets' sizes
+	Min    int     // Smallest size (0 if Count is 0)
+	Max    int     // Largest size
+	Mean   float64 // Mean size
+	StdDev float64 // Population standard deviation of the sizes
+}
+
+// SizeSummary returns summary statistics of the sizes of the given sets in
+// a single pass. See also [SizeHistogram].
+func SizeSummary[E comparable](sets iter.Seq[Set[E]]) SizeStats {
+	var stats SizeStats
+	var m2 float64 // Welford's running sum of squared deviations
+	for set := range sets {
+		n := len(set.set)
+		if stats.Count == 0 || n < stats.Min {
+			stats.Min = n
+		}
+		stats.Max = max(stats.Max, n)
+		stats.Count++
+		stats.Total += n
+		if n == 0 {
+			stats.Empty++
+		}
+		delta := float64(n) - stats.Mean
+		stats.Mean += delta / float64(stats.Count)
+		m2 += delta * (float64(n) - stats.Mean)
+	}
+	if stats.Count > 0 {
+		stats.StdDev = math.Sqrt(m2 / float64(stats.Count))
+	}
+	return stats
+}
@@ -0,0 +1,124 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestDuplicates(t *testing.T) {
+	d := Duplicates(slices.Values([]int{3, 1, 4, 1, 5, 9, 2, 6, 5, 3, 5}))
+	check(sortedStr(d), d.Len(), "{1 3 5}", 3, t)
+	e := Duplicates(slices.Values([]string{"a", "b"}))
+	check(sortedStr(e), e.Len(), "{}", 0, t)
+}
+
+func TestFirstDuplicate(t *testing.T) {
+	x, ok := FirstDuplicate(slices.Values([]int{3, 1, 4, 1, 5, 3}))
+	if !ok || x != 1 {
+		t.Errorf("expected 1 true, got %d %t", x, ok)
+	}
+	s, ok := FirstDuplicate(slices.Values([]string{"a", "b"}))
+	if ok || s != "" {
+		t.Errorf("expected \"\" false, got %q %t", s, ok)
+	}
+}
+
+func TestPairwiseIntersections(t *testing.T) {
+	sets := []Set[int]{New(1, 2, 3, 4), New(3, 4, 5), New(9), New[int]()}
+	m := PairwiseIntersections(sets)
+	check(fmt.Sprintf("%v", m), len(m), "[[4 2 0 0] [2 3 0 0] [0 0 1 0] "+
+		"[0 0 0 0]]", 4, t)
+	j := PairwiseJaccard(sets)
+	check(fmt.Sprintf("%.2f", j), len(j), "[[1.00 0.40 0.00 0.00] "+
+		"[0.40 1.00 0.00 0.00] [0.00 0.00 1.00 0.00] "+
+		"[0.00 0.00 0.00 1.00]]", 4, t)
+	if m := PairwiseIntersections[int](nil); len(m) != 0 {
+		t.Errorf("expected empty matrix, got %v", m)
+	}
+}
+
+func TestAtLeast(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(2, 3, 5)
+	c := New(3, 4, 5, 6)
+	s := AtLeast(2, a, b, c)
+	check(sortedStr(s), s.Len(), "{2 3 4 5}", 4, t)
+	s = AtLeast(3, a, b, c)
+	check(sortedStr(s), s.Len(), "{3}", 1, t)
+	s = AtLeast(1, a, b, c)
+	check(sortedStr(s), s.Len(), "{1 2 3 4 5 6}", 6, t)
+	s = AtLeast(0, a, b)
+	check(sortedStr(s), s.Len(), "{1 2 3 4 5}", 5, t)
+	s = AtLeast(4, a, b, c)
+	check(sortedStr(s), s.Len(), "{}", 0, t)
+}
+
+func TestUniqueToOne(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(2, 3, 5)
+	c := New(3, 4, 6, 7)
+	u := UniqueToOne(a, b, c)
+	if len(u) != 3 {
+		t.Fatalf("expected 3 sets, got %d", len(u))
+	}
+	check(sortedStr(u[0]), u[0].Len(), "{1}", 1, t)
+	check(sortedStr(u[1]), u[1].Len(), "{5}", 1, t)
+	check(sortedStr(u[2]), u[2].Len(), "{6 7}", 2, t)
+	u = UniqueToOne(a, b)
+	x := u[0].Union(u[1])
+	y := a.SymmetricDifference(b)
+	if !x.Equal(y) {
+		t.Errorf("expected %v, got %v", y, x)
+	}
+	if u = UniqueToOne[int](); len(u) != 0 {
+		t.Errorf("expected no sets, got %d", len(u))
+	}
+}
+
+func TestGreedySetCover(t *testing.T) {
+	universe := New(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	candidates := []Set[int]{New(1, 2, 3), New(4, 5, 6, 7, 8), New(1, 4, 9),
+		New(9, 10), New(2, 3, 10), New(7, 8)}
+	cover := GreedySetCover(universe, candidates)
+	check(fmt.Sprintf("%v", cover), len(cover), "[1 0 3]", 3, t)
+	covered := New[int]()
+	for _, i := range cover {
+		covered.Unite(candidates[i])
+	}
+	if !universe.IsSubsetOf(covered) {
+		t.Errorf("expected %v to be covered, got %v", universe, covered)
+	}
+	cover = GreedySetCover(New(1, 2, 99), candidates)
+	check(fmt.Sprintf("%v", cover), len(cover), "[0]", 1, t)
+	cover = GreedySetCover(New[int](), candidates)
+	check(fmt.Sprintf("%v", cover), len(cover), "[]", 0, t)
+}
+
+func TestSizeHistogram(t *testing.T) {
+	sets := []Set[int]{New[int](), New(1), New(1, 2), New(1, 2, 3, 4),
+		New(1), New(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)}
+	counts := SizeHistogram(slices.Values(sets), []int{0, 1, 10})
+	if !slices.Equal(counts, []int{1, 2, 2, 1}) {
+		t.Errorf("expected [1 2 2 1], got %v", counts)
+	}
+	if counts := SizeHistogram(slices.Values(sets), nil); counts[0] != 6 {
+		t.Errorf("expected [6], got %v", counts)
+	}
+}
+
+func TestSizeSummary(t *testing.T) {
+	sets := []Set[int]{New[int](), New(1, 2), New(1, 2, 3, 4), New(9, 8)}
+	stats := SizeSummary(slices.Values(sets))
+	if stats.Count != 4 || stats.Empty != 1 || stats.Total != 8 ||
+		stats.Min != 0 || stats.Max != 4 || stats.Mean != 2 ||
+		math.Abs(stats.StdDev-math.Sqrt2) > 1e-9 {
+		t.Errorf("unexpected %+v", stats)
+	}
+	if stats := SizeSummary(slices.Values([]Set[int]{})); stats !=
+		(SizeStats{}) {
+		t.Errorf("expected zero stats, got %+v", stats)
+	}
+}
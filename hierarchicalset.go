@@ -0,0 +1,82 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "iter"
+
+// HierarchicalSet is a Set whose elements form a hierarchy (e.g., path- or
+// dot-separated IDs such as "news.sport.tennis") given by a parent
+// function, so that membership can be inherited: adding "news.sport"
+// makes [HierarchicalSet.ContainsOrAncestor] true for
+// "news.sport.tennis". This suits permission trees and topic hierarchies.
+type HierarchicalSet[E comparable] struct {
+	set    Set[E]
+	parent func(E) (E, bool)
+}
+
+// NewHierarchical returns a new HierarchicalSet which uses the given
+// parent function and contains the given elements (if any). The parent
+// function must return an element's parent and true, or false for a root;
+// every chain of parents must end at a root.
+func NewHierarchical[E comparable](parent func(E) (E, bool),
+	elements ...E) HierarchicalSet[E] {
+	return HierarchicalSet[E]{set: New(elements...), parent: parent}
+}
+
+// Add adds the given element(s) to the HierarchicalSet.
+func (me *HierarchicalSet[E]) Add(elements ...E) { me.set.Add(elements...) }
+
+// Delete deletes the given element(s) from the HierarchicalSet. This
+// doesn't affect their descendants or ancestors.
+func (me *HierarchicalSet[E]) Delete(elements ...E) {
+	me.set.Delete(elements...)
+}
+
+// Clear deletes all the elements in the HierarchicalSet.
+func (me *HierarchicalSet[E]) Clear() { me.set.Clear() }
+
+// Len returns the number of elements in the HierarchicalSet.
+func (me *HierarchicalSet[E]) Len() int { return me.set.Len() }
+
+// IsEmpty returns true if there are no elements in the HierarchicalSet;
+// otherwise returns false.
+func (me *HierarchicalSet[E]) IsEmpty() bool { return me.set.IsEmpty() }
+
+// Contains returns true if element itself is in the HierarchicalSet;
+// otherwise returns false. See also [HierarchicalSet.ContainsOrAncestor].
+func (me *HierarchicalSet[E]) Contains(element E) bool {
+	return me.set.Contains(element)
+}
+
+// ContainsOrAncestor returns true if element or any of its ancestors is in
+// the HierarchicalSet; otherwise returns false.
+func (me *HierarchicalSet[E]) ContainsOrAncestor(element E) bool {
+	_, ok := me.NearestAncestor(element)
+	return ok
+}
+
+// NearestAncestor returns the nearest of element and its ancestors that
+// is in the HierarchicalSet and true (e.g., to report which rule granted
+// a permission); or the zero value and false if there isn't one.
+func (me *HierarchicalSet[E]) NearestAncestor(element E) (E, bool) {
+	for {
+		if me.set.Contains(element) {
+			return element, true
+		}
+		var ok bool
+		if element, ok = me.parent(element); !ok {
+			var zero E
+			return zero, false
+		}
+	}
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+func (me *HierarchicalSet[E]) All() iter.Seq[E] { return me.set.All() }
+
+// ToSet returns a copy of this HierarchicalSet's elements as a plain Set.
+func (me *HierarchicalSet[E]) ToSet() Set[E] { return me.set.Clone() }
+
+// String returns a human readable string representation of the
+// HierarchicalSet.
+func (me *HierarchicalSet[E]) String() string { return me.set.String() }
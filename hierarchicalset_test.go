@@ -0,0 +1,42 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"strings"
+	"testing"
+)
+
+func dottedParent(s string) (string, bool) {
+	i := strings.LastIndexByte(s, '.')
+	if i == -1 {
+		return "", false
+	}
+	return s[:i], true
+}
+
+func TestHierarchicalSet(t *testing.T) {
+	s := NewHierarchical(dottedParent, "news.sport", "weather")
+	if !s.ContainsOrAncestor("news.sport.tennis.wimbledon") ||
+		!s.ContainsOrAncestor("weather") || s.ContainsOrAncestor("news") ||
+		s.ContainsOrAncestor("news.sportswear") {
+		t.Error("unexpected inherited membership")
+	}
+	if s.Contains("news.sport.tennis") {
+		t.Error("expected only direct membership")
+	}
+	s.Add("news.sport.tennis")
+	if x, ok := s.NearestAncestor("news.sport.tennis.final"); !ok ||
+		x != "news.sport.tennis" {
+		t.Errorf("expected news.sport.tennis, got %q %t", x, ok)
+	}
+	s.Delete("news.sport")
+	if !s.ContainsOrAncestor("news.sport.tennis") ||
+		s.ContainsOrAncestor("news.sport.golf") {
+		t.Error("unexpected membership after delete")
+	}
+	if _, ok := s.NearestAncestor("sport"); ok {
+		t.Error("expected no ancestor")
+	}
+	check(sortedStr(s.ToSet()), s.Len(),
+		"{\"news.sport.tennis\" \"weather\"}", 2, t)
+}
@@ -0,0 +1,78 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// Compress returns a dense relabeling of the given integer set (e.g., a
+// *Set or *AdaptiveSet) so that sparse IDs can index slices or matrices
+// directly: inverse holds the set's elements in ascending order, and
+// mapping maps each element to its index in inverse (so the labels are
+// 0..n-1 and preserve order).
+func Compress[E Integer](s Interface[E]) (mapping map[E]int, inverse []E) {
+	inverse = slices.AppendSeq(make([]E, 0, s.Len()), s.All())
+	slices.Sort(inverse)
+	mapping = make(map[E]int, len(inverse))
+	for i, element := range inverse {
+		mapping[element] = i
+	}
+	return mapping, inverse
+}
+
+// NextAbsent returns the smallest integer >= from that isn't in the given
+// Set and true, e.g., for allocating IDs, ports, or slots; or from and
+// false if every integer from from to E's maximum is in the Set.
+// See also [AdaptiveSet.NextAbsent].
+func NextAbsent[E Integer](s *Set[E], from E) (E, bool) {
+	for x := from; ; x++ {
+		if _, ok := s.set[x]; !ok {
+			return x, true
+		}
+		if x == maxInteger[E]() {
+			return from, false
+		}
+	}
+}
+
+// NextAbsent returns the smallest integer >= from that isn't in the
+// AdaptiveSet and true; or from and false if every integer from from to
+// E's maximum is in the AdaptiveSet. When the AdaptiveSet is using a
+// bitmap this scans a word (64 integers) at a time.
+// See also [NextAbsent].
+func (me *AdaptiveSet[E]) NextAbsent(from E) (E, bool) {
+	if me.mode == adaptiveBitmap {
+		i, ok := me.bit(from)
+		if !ok {
+			return from, true // outside the bitmap so absent
+		}
+		for w := i / 64; w < uint64(len(me.words)); w++ {
+			word := ^me.words[w] // set bits are absent integers
+			if w == i/64 {
+				word &= ^uint64(0) << (i % 64)
+			}
+			if word != 0 {
+				x := me.base + E(w*64+uint64(bits.TrailingZeros64(word)))
+				if x < from { // the bitmap's last word ran past E's max
+					return from, false
+				}
+				return x, true
+			}
+		}
+		x := me.top()
+		if x == maxInteger[E]() {
+			return from, false
+		}
+		return x + 1, true
+	}
+	for x := from; ; x++ {
+		if !me.Contains(x) {
+			return x, true
+		}
+		if x == maxInteger[E]() {
+			return from, false
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNextAbsent(t *testing.T) {
+	s := New(1, 2, 3, 5, 6, 9)
+	for _, c := range [][2]int{{0, 0}, {1, 4}, {4, 4}, {5, 7}, {9, 10}} {
+		if x, ok := NextAbsent(&s, c[0]); !ok || x != c[1] {
+			t.Errorf("expected %d from %d, got %d %t", c[1], c[0], x, ok)
+		}
+	}
+	u := New[uint8]()
+	for i := range 256 {
+		u.Add(uint8(i))
+	}
+	if x, ok := NextAbsent(&u, 10); ok || x != 10 {
+		t.Errorf("expected 10 false, got %d %t", x, ok)
+	}
+	u.Delete(200)
+	if x, ok := NextAbsent(&u, 10); !ok || x != 200 {
+		t.Errorf("expected 200 true, got %d %t", x, ok)
+	}
+}
+
+func TestAdaptiveNextAbsent(t *testing.T) {
+	a := NewAdaptive[int]()
+	for i := range 1000 {
+		if i != 700 {
+			a.Add(i)
+		}
+	}
+	if a.mode != adaptiveBitmap {
+		t.Fatalf("expected bitmap mode, got %d", a.mode)
+	}
+	for _, c := range [][2]int{{-5, -5}, {0, 700}, {700, 700}, {701, 1000},
+		{5000, 5000}} {
+		if x, ok := a.NextAbsent(c[0]); !ok || x != c[1] {
+			t.Errorf("expected %d from %d, got %d %t", c[1], c[0], x, ok)
+		}
+	}
+	b := NewAdaptive(3, 4, 6)
+	if x, ok := b.NextAbsent(3); !ok || x != 5 {
+		t.Errorf("expected 5, got %d %t", x, ok)
+	}
+	c := NewAdaptive[int8]()
+	for i := math.MinInt8; i <= math.MaxInt8; i++ {
+		c.Add(int8(i))
+	}
+	if x, ok := c.NextAbsent(0); ok || x != 0 {
+		t.Errorf("expected 0 false, got %d %t", x, ok)
+	}
+	c.Delete(math.MaxInt8)
+	if x, ok := c.NextAbsent(0); !ok || x != math.MaxInt8 {
+		t.Errorf("expected 127 true, got %d %t", x, ok)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	s := New[int64](1000, -7, 42, 1<<40)
+	mapping, inverse := Compress[int64](&s)
+	check(fmt.Sprint(inverse), len(inverse), "[-7 42 1000 1099511627776]",
+		4, t)
+	for element := range s.All() {
+		if inverse[mapping[element]] != element {
+			t.Errorf("expected %d to round trip", element)
+		}
+	}
+	if mapping[-7] != 0 || mapping[1<<40] != 3 {
+		t.Errorf("expected order-preserving labels, got %v", mapping)
+	}
+	a := NewAdaptive[uint16](9, 3, 5)
+	m, inv := Compress[uint16](&a)
+	check(fmt.Sprint(inv), len(m), "[3 5 9]", 3, t)
+	e := New[int]()
+	m2, inv2 := Compress[int](&e)
+	if len(m2) != 0 || len(inv2) != 0 {
+		t.Error("expected empty relabeling")
+	}
+}
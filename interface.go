@@ -0,0 +1,144 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "iter"
+
+// Interface is the read-only interface shared by the package's set types
+// (e.g., *Set, *FrozenSet, *ValidatedSet, *CanonicalSet,
+// *FrozenStringSet, and *TimeSet), so that [Union], [Intersection], and
+// [Difference] work across them without conversion.
+type Interface[E comparable] interface {
+	Len() int
+	Contains(element E) bool
+	All() iter.Seq[E]
+}
+
+// ConcurrentSet is the interface shared by the concurrency-safe set types,
+// [*SyncSet] and [*ReadMostlySet], so that code can be written against
+// either and the other swapped in, e.g., var seen ConcurrentSet[string] =
+// NewReadMostly[string]().
+type ConcurrentSet[E comparable] interface {
+	Interface[E]
+	Add(elements ...E)
+	AddIfAbsent(element E) bool
+	EnsureWith(element E, onFirst func()) bool
+	Delete(elements ...E)
+	CompareAndDelete(element E) bool
+	Clear()
+	IsEmpty() bool
+	Difference(other Set[E]) Set[E]
+	SymmetricDifference(other Set[E]) Set[E]
+	Intersection(other Set[E]) Set[E]
+	Union(other Set[E]) Set[E]
+	Unite(other Set[E])
+	Clone() Set[E]
+	Equal(other Set[E]) bool
+	IsDisjoint(other Set[E]) bool
+	IsSubsetOf(other Set[E]) bool
+	IsSupersetOf(other Set[E]) bool
+	Snapshot() FrozenSet[E]
+	ToSlice() []E
+	String() string
+}
+
+// mapped is implemented by the set types whose elements are the keys of a
+// plain map, which the algebra functions use as a fast path.
+type mapped[E comparable] interface {
+	elements() map[E]struct{}
+}
+
+func (me *Set[E]) elements() map[E]struct{}          { return me.set }
+func (me *FrozenSet[E]) elements() map[E]struct{}    { return me.set }
+func (me *ValidatedSet[E]) elements() map[E]struct{} { return me.set.set }
+
+// Union returns a new Set that contains the elements from a and from b
+// (which may be of different set types).
+// See also [Set.Union].
+func Union[E comparable](a, b Interface[E]) Set[E] {
+	union := Set[E]{set: make(map[E]struct{}, max(a.Len(), b.Len()))}
+	for _, s := range []Interface[E]{a, b} {
+		if m, ok := s.(mapped[E]); ok {
+			for element := range m.elements() {
+				union.set[element] = struct{}{}
+			}
+		} else {
+			for element := range s.All() {
+				union.set[element] = struct{}{}
+			}
+		}
+	}
+	return union
+}
+
+// Intersection returns a new Set that contains the elements a has in
+// common with b (which may be of different set types). The smaller set is
+// iterated and the larger probed.
+// See also [Set.Intersection].
+func Intersection[E comparable](a, b Interface[E]) Set[E] {
+	if a.Len() > b.Len() {
+		a, b = b, a
+	}
+	ma, aok := a.(mapped[E])
+	mb, bok := b.(mapped[E])
+	if aok && bok {
+		return intersectionOf(ma.elements(), mb.elements(), nil)
+	}
+	intersection := New[E]()
+	for element := range a.All() {
+		if b.Contains(element) {
+			intersection.set[element] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+// Difference returns a new Set that contains the elements which are in a
+// that are not in b (which may be of different set types).
+// See also [Set.Difference].
+func Difference[E comparable](a, b Interface[E]) Set[E] {
+	ma, aok := a.(mapped[E])
+	mb, bok := b.(mapped[E])
+	if aok && bok {
+		return differenceOf(ma.elements(), mb.elements(), nil)
+	}
+	diff := New[E]()
+	for element := range a.All() {
+		if !b.Contains(element) {
+			diff.set[element] = struct{}{}
+		}
+	}
+	return diff
+}
+
+// NewFrom returns a new Set containing the elements of the given set,
+// which may be of any of the package's set types. The result is sized in
+// advance and filled in a single pass.
+// See also [Set.Freeze], [FrozenSet.Thaw], and [NewFrozenStringSetFrom].
+func NewFrom[E comparable](s Interface[E]) Set[E] {
+	set := Set[E]{set: make(map[E]struct{}, s.Len())}
+	if m, ok := s.(mapped[E]); ok {
+		for element := range m.elements() {
+			set.set[element] = struct{}{}
+		}
+	} else {
+		for element := range s.All() {
+			set.set[element] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Find returns an element of the given set for which pred returns true and
+// true; or the zero value and false if there is no such element. It stops
+// at the first match.
+// See also [Set.Find].
+func Find[E comparable](s Interface[E], pred func(E) bool) (E, bool) {
+	for element := range s.All() {
+		if pred(element) {
+			return element, true
+		}
+	}
+	var zero E
+	return zero, false
+}
@@ -0,0 +1,82 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	_ Interface[int]       = &Set[int]{}
+	_ Interface[int]       = &FrozenSet[int]{}
+	_ Interface[int]       = &ValidatedSet[int]{}
+	_ Interface[int]       = &CanonicalSet[int]{}
+	_ Interface[int]       = &HierarchicalSet[int]{}
+	_ Interface[int]       = &SyncSet[int]{}
+	_ Interface[int]       = &ReadMostlySet[int]{}
+	_ Interface[int]       = &ConfiguredSet[int]{}
+	_ Interface[int]       = PackedSet[int]{}
+	_ Interface[int]       = &SortedSet[int]{}
+	_ Interface[int]       = SortedView[int]{}
+	_ Interface[string]    = &FrozenStringSet{}
+	_ Interface[string]    = &CollatedSet[string]{}
+	_ Interface[string]    = &PathSet{}
+	_ Interface[string]    = &PatternSet{}
+	_ Interface[time.Time] = &TimeSet{}
+
+	_ ConcurrentSet[int] = &SyncSet[int]{}
+	_ ConcurrentSet[int] = &ReadMostlySet[int]{}
+)
+
+func TestInterfaceAlgebra(t *testing.T) {
+	a := New("a", "b", "c", "d")
+	f := NewFrozen("c", "d", "e")
+	z := NewFrozenStringSet("d", "e", "f", "g")
+	c := NewCanonical(strings.ToLower, "B", "E", "X")
+	u := Union[string](&a, &z)
+	check(sortedStr(u), u.Len(), `{"a" "b" "c" "d" "e" "f" "g"}`, 7, t)
+	u = Union[string](&f, &c)
+	check(sortedStr(u), u.Len(), `{"b" "c" "d" "e" "x"}`, 5, t)
+	x := Intersection[string](&a, &f)
+	check(sortedStr(x), x.Len(), `{"c" "d"}`, 2, t)
+	x = Intersection[string](&z, &f)
+	check(sortedStr(x), x.Len(), `{"d" "e"}`, 2, t)
+	x = Intersection[string](&a, &c)
+	check(sortedStr(x), x.Len(), `{"b"}`, 1, t)
+	d := Difference[string](&a, &f)
+	check(sortedStr(d), d.Len(), `{"a" "b"}`, 2, t)
+	d = Difference[string](&z, &a)
+	check(sortedStr(d), d.Len(), `{"e" "f" "g"}`, 3, t)
+	d = Difference[string](&a, &c)
+	check(sortedStr(d), d.Len(), `{"a" "c" "d"}`, 3, t)
+}
+
+func TestConversions(t *testing.T) {
+	s := New("one", "two", "three")
+	f := s.Freeze()
+	z := NewFrozenStringSetFrom(&f)
+	check(z.String(), z.Len(), `{"one" "three" "two"}`, 3, t)
+	y := NewFrozenStringSetFrom(&z)
+	if &y.data[0] != &z.data[0] {
+		t.Error("expected frozen string set to be reused")
+	}
+	u := z.ToSet()
+	if !u.Equal(s) {
+		t.Errorf("expected %v, got %v", s, u)
+	}
+	for _, from := range []Interface[string]{&s, &f, &z} {
+		v := NewFrom(from)
+		if !v.Equal(s) {
+			t.Errorf("expected %v, got %v", s, v)
+		}
+		v.Add("four")
+		if s.Contains("four") || f.Contains("four") || z.Contains("four") {
+			t.Error("expected conversion to copy")
+		}
+	}
+	ts := NewTimeSet(time.Hour, time.Date(2025, 1, 1, 9, 30, 0, 0,
+		time.UTC))
+	v := NewFrom[time.Time](&ts)
+	check(v.String(), v.Len(), "{2025-01-01 09:00:00 +0000 UTC}", 1, t)
+}
@@ -0,0 +1,130 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// The checkInvariants methods are only called if debug is true (i.e., when
+// built with -tags setdebug); they panic if an invariant doesn't hold.
+
+func invariantViolated(format string, args ...any) {
+	panic("set: invariant violated: " + fmt.Sprintf(format, args...))
+}
+
+func (me *AdaptiveSet[E]) checkInvariants() {
+	switch me.mode {
+	case adaptiveSmall:
+		if len(me.small) > adaptiveSmallMax {
+			invariantViolated("small mode with %d elements", len(me.small))
+		}
+		for i := 1; i < len(me.small); i++ {
+			if me.small[i-1] >= me.small[i] {
+				invariantViolated("small mode unsorted at %d: %v", i,
+					me.small)
+			}
+		}
+	case adaptiveBitmap:
+		count := 0
+		for _, word := range me.words {
+			count += bits.OnesCount64(word)
+		}
+		if count != me.count {
+			invariantViolated("bitmap has %d bits set but count is %d",
+				count, me.count)
+		}
+		if me.count <= adaptiveSmallMax/2 {
+			invariantViolated("bitmap mode with only %d elements", me.count)
+		}
+	case adaptiveHash:
+		if me.hash.Len() <= adaptiveSmallMax/2 {
+			invariantViolated("hash mode with only %d elements",
+				me.hash.Len())
+		}
+		for element := range me.hash.set {
+			if element < me.lo || element > me.hi {
+				invariantViolated("hash element %v outside [%v, %v]",
+					element, me.lo, me.hi)
+			}
+		}
+	default:
+		invariantViolated("unknown adaptive mode %d", me.mode)
+	}
+}
+
+func (me *TieredSet[E]) checkInvariants() {
+	if len(me.hot) != me.lru.Len() {
+		invariantViolated("hot tier has %d elements but LRU has %d",
+			len(me.hot), me.lru.Len())
+	}
+	if len(me.hot) > me.hotSize {
+		invariantViolated("hot tier has %d elements; maximum is %d",
+			len(me.hot), me.hotSize)
+	}
+	for item := me.lru.Front(); item != nil; item = item.Next() {
+		if me.hot[item.Value.(E)] != item {
+			invariantViolated("LRU element %v not in hot tier", item.Value)
+		}
+	}
+}
+
+func (me *FrozenStringSet) checkInvariants() {
+	blocks := (me.n + frontCodingBlockSize - 1) / frontCodingBlockSize
+	if blocks != me.blocks() {
+		invariantViolated("%d strings need %d blocks, got %d", me.n, blocks,
+			me.blocks())
+	}
+	n := 0
+	prev := ""
+	for element := range me.All() {
+		if n > 0 && element <= prev {
+			invariantViolated("strings unsorted at %d: %q <= %q", n,
+				element, prev)
+		}
+		prev = element
+		n++
+	}
+	if n != me.n {
+		invariantViolated("%d strings but length is %d", n, me.n)
+	}
+}
+
+func (me *SortedSet[E]) checkInvariants() {
+	var check func(node *sortedNode[E]) (int, int)
+	check = func(node *sortedNode[E]) (height, size int) {
+		if node == nil {
+			return 0, 0
+		}
+		if node.left != nil &&
+			me.compare(node.left.element, node.element) >= 0 {
+			invariantViolated("tree unordered at %v", node.element)
+		}
+		if node.right != nil &&
+			me.compare(node.right.element, node.element) <= 0 {
+			invariantViolated("tree unordered at %v", node.element)
+		}
+		lh, ls := check(node.left)
+		rh, rs := check(node.right)
+		if lh-rh > 1 || rh-lh > 1 {
+			invariantViolated("tree unbalanced at %v: %d vs %d",
+				node.element, lh, rh)
+		}
+		height, size = 1+max(lh, rh), 1+ls+rs
+		if node.height != height || node.size != size {
+			invariantViolated("node %v has height %d size %d; expected "+
+				"%d and %d", node.element, node.height, node.size, height,
+				size)
+		}
+		return height, size
+	}
+	check(me.root)
+	prev, first := *new(E), true
+	for element := range me.All() {
+		if !first && me.compare(element, prev) <= 0 {
+			invariantViolated("elements unsorted: %v <= %v", element, prev)
+		}
+		prev, first = element, false
+	}
+}
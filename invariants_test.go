@@ -0,0 +1,44 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"strings"
+	"testing"
+)
+
+func expectViolation(t *testing.T, what string, check func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		r := recover()
+		if msg, ok := r.(string); !ok ||
+			!strings.HasPrefix(msg, "set: invariant violated: ") {
+			t.Errorf("expected %s invariant violation, got %v", what, r)
+		}
+	}()
+	check()
+}
+
+func TestInvariants(t *testing.T) {
+	a := NewAdaptive[int]()
+	for i := range 100 {
+		a.Add(i)
+	}
+	a.checkInvariants()
+	a.count--
+	expectViolation(t, "bitmap count", a.checkInvariants)
+	b := NewAdaptive(3, 1, 2)
+	b.checkInvariants()
+	b.small[0] = 9
+	expectViolation(t, "small order", b.checkInvariants)
+	cold := &fakeCold{set: New[int]()}
+	s := NewTiered(2, cold, nil)
+	_ = s.Add(1, 2, 3)
+	s.checkInvariants()
+	s.lru.Remove(s.lru.Front())
+	expectViolation(t, "tiered LRU", s.checkInvariants)
+	f := NewFrozenStringSet(paths(40)...)
+	f.checkInvariants()
+	f.n++
+	expectViolation(t, "frozen length", f.checkInvariants)
+}
@@ -0,0 +1,58 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Join returns the given string set's elements joined by sep, in no
+// particular order. See also [JoinSorted].
+func Join[E ~string](s Interface[E], sep string) string {
+	var out strings.Builder
+	for element := range s.All() {
+		if out.Len() > 0 {
+			out.WriteString(sep)
+		}
+		out.WriteString(string(element))
+	}
+	return out.String()
+}
+
+// JoinSorted returns the given string set's elements in ascending order
+// joined by sep, e.g., for stable log lines or human readable summaries.
+func JoinSorted[E ~string](s Interface[E], sep string) string {
+	return strings.Join(sortedStrings(s), sep)
+}
+
+// QuotedJoin returns the given string set's elements in ascending order,
+// each passed through quote, joined by sep. If quote is nil,
+// [strconv.Quote] is used. For example, to build an SQL list:
+//
+//	sqlQuote := func(s string) string {
+//		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+//	}
+//	query := "... WHERE name IN (" + set.QuotedJoin(&names, ", ",
+//		sqlQuote) + ")"
+func QuotedJoin[E ~string](s Interface[E], sep string,
+	quote func(string) string) string {
+	if quote == nil {
+		quote = strconv.Quote
+	}
+	elements := sortedStrings(s)
+	for i, element := range elements {
+		elements[i] = quote(element)
+	}
+	return strings.Join(elements, sep)
+}
+
+func sortedStrings[E ~string](s Interface[E]) []string {
+	elements := make([]string, 0, s.Len())
+	for element := range s.All() {
+		elements = append(elements, string(element))
+	}
+	slices.Sort(elements)
+	return elements
+}
@@ -0,0 +1,36 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJoin(t *testing.T) {
+	type tag string
+	s := New[tag]("b", "a", "c")
+	if j := Join(&s, ","); len(j) != 5 || strings.Count(j, ",") != 2 {
+		t.Errorf("unexpected %q", j)
+	}
+	if j := JoinSorted(&s, ", "); j != "a, b, c" {
+		t.Errorf("expected \"a, b, c\", got %q", j)
+	}
+	e := New[string]()
+	if j := Join(&e, ","); j != "" {
+		t.Errorf("expected empty, got %q", j)
+	}
+}
+
+func TestQuotedJoin(t *testing.T) {
+	s := New("it's", "x\ty")
+	if j := QuotedJoin(&s, " ", nil); j != `"it's" "x\ty"` {
+		t.Errorf("unexpected %s", j)
+	}
+	sqlQuote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	f := NewFrozen("o'neil", "smith")
+	if j := QuotedJoin(&f, ", ", sqlQuote); j != "'o''neil', 'smith'" {
+		t.Errorf("unexpected %s", j)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "encoding/json"
+
+// MarshalJSON implements json.Marshaler; the Set is encoded as a JSON
+// array of its elements in no particular order. (This method has a value
+// receiver so that Sets marshal correctly even when not addressable.)
+func (me Set[E]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(me.ToSlice())
+}
+
+// UnmarshalJSON implements json.Unmarshaler; the Set's elements are
+// replaced by those in the given JSON array (duplicates are ignored). A
+// JSON null produces an empty Set.
+func (me *Set[E]) UnmarshalJSON(data []byte) error {
+	var elements []E
+	if err := json.Unmarshal(data, &elements); err != nil {
+		return err
+	}
+	me.set = make(map[E]struct{}, len(elements))
+	me.peak = 0
+	me.Add(elements...)
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	s := New(3, 1, 2)
+	raw, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var u Set[int]
+	if err := json.Unmarshal(raw, &u); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(u), u.Len(), "{1 2 3}", 3, t)
+	type record struct {
+		Tags Set[string] `json:"tags"`
+	}
+	r := record{Tags: New("b", "a")}
+	raw, err = json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var q record
+	if err := json.Unmarshal(raw, &q); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(q.Tags), q.Tags.Len(), "{\"a\" \"b\"}", 2, t)
+	if err := json.Unmarshal([]byte(`[1, 2, 2, 5]`), &u); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(u), u.Len(), "{1 2 5}", 3, t)
+	if err := json.Unmarshal([]byte(`null`), &u); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(u), u.Len(), "{}", 0, t)
+	if err := json.Unmarshal([]byte(`{"a": 1}`), &u); err == nil {
+		t.Error("expected error unmarshaling object")
+	}
+	if err := json.Unmarshal([]byte(`["x"]`), &u); err == nil {
+		t.Error("expected error unmarshaling wrong element type")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+//go:build go1.27 && goexperiment.jsonv2
+
+package set
+
+import (
+	"encoding/json/jsontext"
+	json "encoding/json/v2"
+	"fmt"
+)
+
+// MarshalJSONTo implements the encoding/json/v2 MarshalerTo interface.
+// The Set is streamed as a JSON array one element at a time, so no
+// intermediate slice is built. (This method has a value receiver so that
+// Sets marshal correctly even when not addressable.)
+func (me Set[E]) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+		return err
+	}
+	for element := range me.set {
+		if err := json.MarshalEncode(enc, element); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndArray)
+}
+
+// UnmarshalJSONFrom implements the encoding/json/v2 UnmarshalerFrom
+// interface. The Set's elements are replaced by those streamed from the
+// JSON array (duplicates are ignored). A JSON null produces an empty Set.
+func (me *Set[E]) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	me.set = make(map[E]struct{})
+	me.peak = 0
+	token, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	switch token.Kind() {
+	case 'n':
+		return nil
+	case '[':
+	default:
+		return fmt.Errorf("cannot unmarshal JSON %s into a set", token.Kind())
+	}
+	for dec.PeekKind() != ']' {
+		var element E
+		if err := json.UnmarshalDecode(dec, &element); err != nil {
+			return err
+		}
+		me.set[element] = struct{}{}
+	}
+	_, err = dec.ReadToken()
+	return err
+}
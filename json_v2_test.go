@@ -0,0 +1,42 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+//go:build go1.27 && goexperiment.jsonv2
+
+package set
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	json "encoding/json/v2"
+	"testing"
+)
+
+func TestJSONv2(t *testing.T) {
+	s := New("x", "y", "z")
+	var buf bytes.Buffer
+	if err := json.MarshalWrite(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	var u Set[string]
+	if err := json.UnmarshalRead(&buf, &u); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(u), u.Len(), "{\"x\" \"y\" \"z\"}", 3, t)
+	dec := jsontext.NewDecoder(bytes.NewReader([]byte(`[1,2,1] [3]`)))
+	var v Set[int]
+	if err := v.UnmarshalJSONFrom(dec); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(v), v.Len(), "{1 2}", 2, t)
+	if err := v.UnmarshalJSONFrom(dec); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(v), v.Len(), "{3}", 1, t)
+	if err := json.Unmarshal([]byte(`null`), &v); err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(v), v.Len(), "{}", 0, t)
+	if err := json.Unmarshal([]byte(`{}`), &v); err == nil {
+		t.Error("expected error unmarshaling object")
+	}
+}
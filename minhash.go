@@ -0,0 +1,169 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"slices"
+)
+
+// MinHash is a MinHash sketch of a set: a fixed-size signature from which
+// the Jaccard similarity of two sets can be estimated without comparing
+// their elements, e.g., for near-duplicate detection across millions of
+// sets (see also [LSHIndex]). The estimate's standard error is about
+// 1/√k for a sketch of k hash functions. Elements are hashed with
+// [StableHash], so signatures agree between processes and machines.
+type MinHash[E comparable] struct {
+	mins []uint64
+}
+
+// NewMinHash returns a new empty MinHash sketch using k (at least 1) hash
+// functions; the type must be specified since it can't be inferred.
+func NewMinHash[E comparable](k int) *MinHash[E] {
+	mins := make([]uint64, max(1, k))
+	for i := range mins {
+		mins[i] = math.MaxUint64
+	}
+	return &MinHash[E]{mins: mins}
+}
+
+// MinHashOf returns a new MinHash sketch using k hash functions of the
+// given set's elements. Returns an error if the elements aren't stable
+// hashable.
+func MinHashOf[E comparable](set Interface[E], k int) (*MinHash[E], error) {
+	sketch := NewMinHash[E](k)
+	for element := range set.All() {
+		if err := sketch.Add(element); err != nil {
+			return nil, err
+		}
+	}
+	return sketch, nil
+}
+
+// Add adds the given element(s) to the sketch. It stops and returns an
+// error if an element isn't stable hashable.
+func (me *MinHash[E]) Add(elements ...E) error {
+	for _, element := range elements {
+		h, err := StableHash(element)
+		if err != nil {
+			return err
+		}
+		for i := range me.mins {
+			// Each seeded remix of h acts as a random permutation.
+			if v := mix64(h ^ minHashSeed(i)); v < me.mins[i] {
+				me.mins[i] = v
+			}
+		}
+	}
+	return nil
+}
+
+// K returns the number of hash functions the sketch uses.
+func (me *MinHash[E]) K() int { return len(me.mins) }
+
+// Signature returns a copy of the sketch's signature.
+func (me *MinHash[E]) Signature() []uint64 { return slices.Clone(me.mins) }
+
+// EstimateJaccard returns an estimate of the Jaccard similarity (the size
+// of the intersection over the size of the union) of the sets sketched by
+// this MinHash and the other one, which must use the same number of hash
+// functions (otherwise 0 is returned).
+func (me *MinHash[E]) EstimateJaccard(other *MinHash[E]) float64 {
+	if len(me.mins) != len(other.mins) {
+		return 0
+	}
+	same := 0
+	for i, v := range me.mins {
+		if v == other.mins[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(len(me.mins))
+}
+
+// Union merges the other sketch into this one, so that it sketches the
+// union of the two sets. The other sketch must use the same number of
+// hash functions (otherwise Union does nothing and returns false).
+func (me *MinHash[E]) Union(other *MinHash[E]) bool {
+	if len(me.mins) != len(other.mins) {
+		return false
+	}
+	for i, v := range other.mins {
+		me.mins[i] = min(me.mins[i], v)
+	}
+	return true
+}
+
+func minHashSeed(i int) uint64 {
+	return mix64(uint64(i+1) * 0x9e3779b97f4a7c15)
+}
+
+// LSHIndex is a locality-sensitive hashing index of [MinHash] signatures,
+// keyed by ID, which finds candidate near-duplicates without comparing
+// against every indexed signature. Each signature is split into bands of
+// rows; two signatures are candidates if all the rows of any band agree.
+// With b bands of r rows, sets of Jaccard similarity s become candidates
+// with probability 1-(1-sʳ)ᵇ, so more bands find less similar sets.
+// LSHIndex is not safe for concurrent use.
+type LSHIndex[K comparable] struct {
+	bands   int
+	buckets []map[uint64]Set[K] // per band: band hash → IDs
+}
+
+// NewLSHIndex returns a new empty LSHIndex which splits signatures into
+// the given number of bands (at least 1); the type must be specified since
+// it can't be inferred.
+func NewLSHIndex[K comparable](bands int) *LSHIndex[K] {
+	bands = max(1, bands)
+	buckets := make([]map[uint64]Set[K], bands)
+	for i := range buckets {
+		buckets[i] = make(map[uint64]Set[K])
+	}
+	return &LSHIndex[K]{bands: bands, buckets: buckets}
+}
+
+// Add indexes the given signature (see [MinHash.Signature]) under id.
+func (me *LSHIndex[K]) Add(id K, signature []uint64) {
+	for i, key := range LSHBands(signature, me.bands) {
+		ids, ok := me.buckets[i][key]
+		if !ok {
+			ids = New[K]()
+			me.buckets[i][key] = ids
+		}
+		ids.set[id] = struct{}{}
+	}
+}
+
+// Candidates returns a new Set of the IDs of the indexed signatures that
+// share at least one band with the given signature. These are likely
+// (but not certain) to be similar; check them with
+// [MinHash.EstimateJaccard] or an exact comparison.
+func (me *LSHIndex[K]) Candidates(signature []uint64) Set[K] {
+	candidates := New[K]()
+	for i, key := range LSHBands(signature, me.bands) {
+		candidates.Unite(me.buckets[i][key])
+	}
+	return candidates
+}
+
+// LSHBands returns one hash per band for the given signature split into
+// the given number of bands (at least 1) of equal numbers of rows; any
+// rows left over are ignored. Signatures whose hashes agree in a band very
+// probably agree in all that band's rows.
+func LSHBands(signature []uint64, bands int) []uint64 {
+	bands = max(1, min(bands, len(signature)))
+	rows := len(signature) / bands
+	keys := make([]uint64, bands)
+	var buf [8]byte
+	for i := range keys {
+		hasher := fnv.New64a()
+		for _, v := range signature[i*rows : (i+1)*rows] {
+			binary.BigEndian.PutUint64(buf[:], v)
+			hasher.Write(buf[:])
+		}
+		keys[i] = hasher.Sum64()
+	}
+	return keys
+}
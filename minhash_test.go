@@ -0,0 +1,73 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMinHash(t *testing.T) {
+	a, b := New[int](), New[int]()
+	for i := range 1000 {
+		a.Add(i)
+		b.Add(i + 500) // Jaccard = 500/1500
+	}
+	ma, err := MinHashOf(&a, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mb, _ := MinHashOf(&b, 256)
+	exact := 1.0 / 3
+	if est := ma.EstimateJaccard(mb); math.Abs(est-exact) > 0.1 {
+		t.Errorf("expected about %.2f, got %.2f", exact, est)
+	}
+	if ma.EstimateJaccard(ma) != 1 || ma.K() != 256 {
+		t.Error("expected identical sketches to agree")
+	}
+	if ma.EstimateJaccard(NewMinHash[int](8)) != 0 ||
+		ma.Union(NewMinHash[int](8)) {
+		t.Error("expected mismatched sketches to be rejected")
+	}
+	u := New[int]()
+	u.Unite(a)
+	u.Unite(b)
+	mu, _ := MinHashOf(&u, 256)
+	ma.Union(mb)
+	if ma.EstimateJaccard(mu) != 1 {
+		t.Error("expected union of sketches to sketch the union")
+	}
+	sig := mu.Signature()
+	sig[0] = 0
+	if mu.Signature()[0] == 0 {
+		t.Error("expected Signature to return a copy")
+	}
+	type pair struct{ x int }
+	if err := NewMinHash[pair](4).Add(pair{1}); err == nil {
+		t.Error("expected error for unhashable element")
+	}
+}
+
+func TestLSHIndex(t *testing.T) {
+	base := New[string]()
+	for _, w := range []string{"the", "quick", "brown", "fox", "jumps",
+		"over", "lazy", "dog", "again", "today"} {
+		base.Add(w)
+	}
+	near := base.Clone()
+	near.Delete("today")
+	near.Add("tonight")
+	far := New("lorem", "ipsum", "dolor", "sit", "amet")
+	index := NewLSHIndex[string](32)
+	for id, s := range map[string]Set[string]{"near": near, "far": far} {
+		sketch, _ := MinHashOf(&s, 128)
+		index.Add(id, sketch.Signature())
+	}
+	sketch, _ := MinHashOf(&base, 128)
+	candidates := index.Candidates(sketch.Signature())
+	if !candidates.Contains("near") || candidates.Contains("far") {
+		t.Errorf("unexpected candidates %v", candidates.String())
+	}
+	if keys := LSHBands([]uint64{1, 2, 3, 4, 5}, 2); len(keys) != 2 {
+		t.Errorf("expected 2 band keys, got %d", len(keys))
+	}
+}
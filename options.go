@@ -0,0 +1,215 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Option configures a [ConfiguredSet]; see [NewWith].
+type Option[E comparable] func(*ConfiguredSet[E])
+
+// WithCapacity returns an Option that preallocates room for n elements.
+func WithCapacity[E comparable](n int) Option[E] {
+	return func(set *ConfiguredSet[E]) { set.capacity = n }
+}
+
+// WithNormalizer returns an Option that passes every element through
+// normalize (e.g., to trim space or fold case) before it is stored or
+// looked up, as for a [CanonicalSet].
+func WithNormalizer[E comparable](normalize func(E) E) Option[E] {
+	return func(set *ConfiguredSet[E]) { set.normalize = normalize }
+}
+
+// WithValidator returns an Option that makes [ConfiguredSet.Add] reject
+// (normalized) elements for which validate returns an error, as for a
+// [ValidatedSet].
+func WithValidator[E comparable](validate func(E) error) Option[E] {
+	return func(set *ConfiguredSet[E]) { set.validate = validate }
+}
+
+// WithFormatter returns an Option that makes [ConfiguredSet.String] format
+// each element using format (and sort them), rather than using fmt with
+// "%v" in no particular order.
+func WithFormatter[E comparable](format func(E) string) Option[E] {
+	return func(set *ConfiguredSet[E]) { set.format = format }
+}
+
+// WithLocking returns an Option that makes the ConfiguredSet safe for
+// concurrent use.
+func WithLocking[E comparable]() Option[E] {
+	return func(set *ConfiguredSet[E]) { set.locking = true }
+}
+
+// ConfiguredSet is a Set whose capacity, normalization, validation, string
+// format, and thread-safety are chosen by [Option]s passed to [NewWith],
+// so that combinations of these don't each need their own type. Each
+// feature costs nothing unless its Option is given. A ConfiguredSet must
+// not be copied after first use.
+type ConfiguredSet[E comparable] struct {
+	set       Set[E]
+	capacity  int
+	normalize func(E) E
+	validate  func(E) error
+	format    func(E) string
+	locking   bool
+	mutex     sync.RWMutex
+}
+
+// NewWith returns a new empty ConfiguredSet configured by the given
+// options, e.g.,
+//
+//	tags := set.NewWith(set.WithNormalizer(strings.ToLower),
+//		set.WithValidator(validTag), set.WithLocking[string]())
+func NewWith[E comparable](options ...Option[E]) *ConfiguredSet[E] {
+	set := &ConfiguredSet[E]{}
+	for _, option := range options {
+		option(set)
+	}
+	set.set = Set[E]{set: make(map[E]struct{}, set.capacity)}
+	return set
+}
+
+// Add adds the (normalized) given element(s) to the ConfiguredSet if they
+// are all valid; otherwise it adds none of them and returns an error for
+// the first invalid element, wrapping both [ErrInvalidElement] and the
+// validator's error. Without a validator Add always returns nil.
+func (me *ConfiguredSet[E]) Add(elements ...E) error {
+	normalized := elements
+	if me.normalize != nil {
+		normalized = make([]E, len(elements))
+		for i, element := range elements {
+			normalized[i] = me.normalize(element)
+		}
+	}
+	if me.validate != nil {
+		for _, element := range normalized {
+			if err := me.validate(element); err != nil {
+				return fmt.Errorf("%w %v: %w", ErrInvalidElement, element,
+					err)
+			}
+		}
+	}
+	me.lock()
+	defer me.unlock()
+	me.set.Add(normalized...)
+	return nil
+}
+
+// Delete deletes the (normalized) given element(s) from the
+// ConfiguredSet.
+func (me *ConfiguredSet[E]) Delete(elements ...E) {
+	me.lock()
+	defer me.unlock()
+	for _, element := range elements {
+		me.set.Delete(me.normalized(element))
+	}
+}
+
+// Clear deletes all the elements in the ConfiguredSet.
+func (me *ConfiguredSet[E]) Clear() {
+	me.lock()
+	defer me.unlock()
+	me.set.Clear()
+}
+
+// Len returns the number of elements in the ConfiguredSet.
+func (me *ConfiguredSet[E]) Len() int {
+	me.rlock()
+	defer me.runlock()
+	return me.set.Len()
+}
+
+// IsEmpty returns true if there are no elements in the ConfiguredSet;
+// otherwise returns false.
+func (me *ConfiguredSet[E]) IsEmpty() bool { return me.Len() == 0 }
+
+// Contains returns true if element's normalized form is in the
+// ConfiguredSet; otherwise returns false.
+func (me *ConfiguredSet[E]) Contains(element E) bool {
+	element = me.normalized(element)
+	me.rlock()
+	defer me.runlock()
+	return me.set.Contains(element)
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+// With locking the loop iterates over a copy of the elements, so the loop
+// body may change the ConfiguredSet.
+func (me *ConfiguredSet[E]) All() iter.Seq[E] {
+	if !me.locking {
+		return me.set.All()
+	}
+	return func(yield func(E) bool) {
+		for _, element := range me.ToSlice() {
+			if !yield(element) {
+				return
+			}
+		}
+	}
+}
+
+// ToSlice returns this ConfiguredSet's elements as an unsorted slice.
+func (me *ConfiguredSet[E]) ToSlice() []E {
+	me.rlock()
+	defer me.runlock()
+	return me.set.ToSlice()
+}
+
+// ToSet returns a copy of this ConfiguredSet's elements as a plain Set.
+func (me *ConfiguredSet[E]) ToSet() Set[E] {
+	me.rlock()
+	defer me.runlock()
+	return me.set.Clone()
+}
+
+// String returns a human readable string representation of the
+// ConfiguredSet (see [WithFormatter]).
+func (me *ConfiguredSet[E]) String() string {
+	if me.format == nil {
+		me.rlock()
+		defer me.runlock()
+		return me.set.String()
+	}
+	texts := make([]string, 0, me.Len())
+	for _, element := range me.ToSlice() {
+		texts = append(texts, me.format(element))
+	}
+	slices.Sort(texts)
+	return "{" + strings.Join(texts, " ") + "}"
+}
+
+func (me *ConfiguredSet[E]) normalized(element E) E {
+	if me.normalize != nil {
+		return me.normalize(element)
+	}
+	return element
+}
+
+func (me *ConfiguredSet[E]) lock() {
+	if me.locking {
+		me.mutex.Lock()
+	}
+}
+
+func (me *ConfiguredSet[E]) unlock() {
+	if me.locking {
+		me.mutex.Unlock()
+	}
+}
+
+func (me *ConfiguredSet[E]) rlock() {
+	if me.locking {
+		me.mutex.RLock()
+	}
+}
+
+func (me *ConfiguredSet[E]) runlock() {
+	if me.locking {
+		me.mutex.RUnlock()
+	}
+}
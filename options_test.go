@@ -0,0 +1,69 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewWith(t *testing.T) {
+	s := NewWith(WithCapacity[string](8),
+		WithNormalizer(strings.ToLower),
+		WithValidator(func(s string) error {
+			if s == "" {
+				return errors.New("empty tag")
+			}
+			return nil
+		}),
+		WithFormatter(func(s string) string { return "#" + s }))
+	if err := s.Add("Go", "RUST", "go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add("zig", ""); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("expected invalid element error, got %v", err)
+	}
+	if s.String() != "{#go #rust}" || !s.Contains("GO") {
+		t.Errorf("unexpected %v", s.String())
+	}
+	s.Delete("Rust")
+	check(sortedStr(s.ToSet()), s.Len(), "{\"go\"}", 1, t)
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Error("unexpected nonempty")
+	}
+	plain := NewWith[int]()
+	plain.Add(3, 1)
+	n := 0
+	for x := range plain.All() {
+		n += x
+	}
+	if n != 4 || plain.Len() != 2 {
+		t.Errorf("unexpected %v", plain.String())
+	}
+}
+
+func TestNewWithLocking(t *testing.T) {
+	s := NewWith(WithLocking[int]())
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				s.Add(g*500 + i)
+				s.Contains(i)
+				if i%50 == 0 {
+					for x := range s.All() {
+						s.Delete(x + 1000000) // may change while iterating
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 2000 {
+		t.Errorf("expected 2000, got %d", s.Len())
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// PackedSetStore stores many small sets, keyed by ID, in one shared
+// backing array, e.g., for millions of follower or tag sets. This avoids
+// the per-map overhead of a map[K]Set[E], at the cost of linear-time
+// Contains within each set, so it suits sets of at most a few dozen
+// elements. Changed sets are moved to the end of the backing array; the
+// space they leave is reclaimed automatically once it exceeds the space in
+// use (or by calling [PackedSetStore.Compact]). Reverse lookups (see
+// [PackedSetStore.AllContaining]) scan every set unless an inverted index
+// is enabled with [PackedSetStore.SetIndexed].
+// PackedSetStore is not safe for concurrent use.
+type PackedSetStore[K, E comparable] struct {
+	pool  []E
+	spans map[K]packedSpan
+	waste int          // elements in pool not in any span
+	index map[E]Set[K] // element → IDs of the sets containing it (if on)
+}
+
+type packedSpan struct{ start, end int }
+
+// NewPackedSetStore returns a new empty PackedSetStore; the types must be
+// specified since they can't be inferred.
+func NewPackedSetStore[K, E comparable]() *PackedSetStore[K, E] {
+	return &PackedSetStore[K, E]{spans: make(map[K]packedSpan)}
+}
+
+// Len returns the number of sets in the store.
+func (me *PackedSetStore[K, E]) Len() int { return len(me.spans) }
+
+// IDs returns an iterator over the IDs of the store's sets, in no
+// particular order.
+func (me *PackedSetStore[K, E]) IDs() iter.Seq[K] {
+	return maps.Keys(me.spans)
+}
+
+// Get returns a view of the set with the given ID and true; or an empty
+// view and false if there is no such set. The view is only valid until
+// the store is next changed.
+func (me *PackedSetStore[K, E]) Get(id K) (PackedSet[E], bool) {
+	span, ok := me.spans[id]
+	if !ok {
+		return PackedSet[E]{}, false
+	}
+	return PackedSet[E]{me.pool[span.start:span.end:span.end]}, true
+}
+
+// Put sets the set with the given ID to contain the given elements
+// (creating the set if it doesn't exist), replacing any it had.
+func (me *PackedSetStore[K, E]) Put(id K, elements ...E) {
+	me.release(id)
+	start := len(me.pool)
+	for _, element := range elements {
+		if !slices.Contains(me.pool[start:], element) {
+			me.pool = append(me.pool, element)
+			me.indexAdd(element, id)
+		}
+	}
+	me.spans[id] = packedSpan{start, len(me.pool)}
+	me.maybeCompact()
+}
+
+// Add adds the given element(s) to the set with the given ID (creating the
+// set if it doesn't exist).
+func (me *PackedSetStore[K, E]) Add(id K, elements ...E) {
+	span, ok := me.spans[id]
+	if !ok {
+		me.Put(id, elements...)
+		return
+	}
+	if span.end != len(me.pool) { // move it to the end so it can grow
+		me.pool = append(me.pool, me.pool[span.start:span.end]...)
+		me.waste += span.end - span.start
+		span = packedSpan{len(me.pool) - (span.end - span.start),
+			len(me.pool)}
+	}
+	for _, element := range elements {
+		if !slices.Contains(me.pool[span.start:], element) {
+			me.pool = append(me.pool, element)
+			me.indexAdd(element, id)
+		}
+	}
+	span.end = len(me.pool)
+	me.spans[id] = span
+	me.maybeCompact()
+}
+
+// Delete deletes the given element(s) from the set with the given ID (if
+// it exists). The set is kept even if it becomes empty.
+func (me *PackedSetStore[K, E]) Delete(id K, elements ...E) {
+	span, ok := me.spans[id]
+	if !ok {
+		return
+	}
+	members := me.pool[span.start:span.end]
+	kept := slices.DeleteFunc(members, func(element E) bool {
+		if slices.Contains(elements, element) {
+			me.indexDelete(element, id)
+			return true
+		}
+		return false
+	})
+	me.waste += len(members) - len(kept)
+	span.end = span.start + len(kept)
+	me.spans[id] = span
+	me.maybeCompact()
+}
+
+// DeleteSet deletes the set with the given ID and returns true; or returns
+// false if there is no such set.
+func (me *PackedSetStore[K, E]) DeleteSet(id K) bool {
+	_, ok := me.spans[id]
+	me.release(id)
+	me.maybeCompact()
+	return ok
+}
+
+// Compact rewrites the backing array to hold only the elements in use.
+func (me *PackedSetStore[K, E]) Compact() {
+	pool := make([]E, 0, len(me.pool)-me.waste)
+	for id, span := range me.spans {
+		start := len(pool)
+		pool = append(pool, me.pool[span.start:span.end]...)
+		me.spans[id] = packedSpan{start, len(pool)}
+	}
+	me.pool = pool
+	me.waste = 0
+}
+
+// SetIndexed sets whether the store maintains an inverted index from
+// elements to the IDs of the sets containing them, which makes
+// [PackedSetStore.AllContaining] fast at the cost of a map entry per
+// element-set pair. The default is false. Turning it on builds the index
+// from the current sets.
+func (me *PackedSetStore[K, E]) SetIndexed(on bool) {
+	if !on {
+		me.index = nil
+		return
+	}
+	if me.index != nil {
+		return
+	}
+	me.index = make(map[E]Set[K])
+	for id, span := range me.spans {
+		for _, element := range me.pool[span.start:span.end] {
+			me.indexAdd(element, id)
+		}
+	}
+}
+
+// Indexed returns true if the store maintains an inverted index; otherwise
+// returns false. See [PackedSetStore.SetIndexed].
+func (me *PackedSetStore[K, E]) Indexed() bool { return me.index != nil }
+
+// AllContaining returns a new Set of the IDs of the sets that contain
+// element. This uses the inverted index if there is one; otherwise it
+// scans every set.
+func (me *PackedSetStore[K, E]) AllContaining(element E) Set[K] {
+	if me.index != nil {
+		if ids, ok := me.index[element]; ok {
+			return ids.Clone()
+		}
+		return New[K]()
+	}
+	ids := New[K]()
+	for id, span := range me.spans {
+		if slices.Contains(me.pool[span.start:span.end], element) {
+			ids.set[id] = struct{}{}
+		}
+	}
+	return ids
+}
+
+func (me *PackedSetStore[K, E]) release(id K) {
+	if span, ok := me.spans[id]; ok {
+		for _, element := range me.pool[span.start:span.end] {
+			me.indexDelete(element, id)
+		}
+		me.waste += span.end - span.start
+		delete(me.spans, id)
+	}
+}
+
+func (me *PackedSetStore[K, E]) indexAdd(element E, id K) {
+	if me.index == nil {
+		return
+	}
+	ids, ok := me.index[element]
+	if !ok {
+		ids = New[K]()
+		me.index[element] = ids
+	}
+	ids.set[id] = struct{}{}
+}
+
+func (me *PackedSetStore[K, E]) indexDelete(element E, id K) {
+	if ids, ok := me.index[element]; ok {
+		delete(ids.set, id)
+		if len(ids.set) == 0 {
+			delete(me.index, element)
+		}
+	}
+}
+
+func (me *PackedSetStore[K, E]) maybeCompact() {
+	if me.waste > 64 && me.waste > len(me.pool)-me.waste {
+		me.Compact()
+	}
+}
+
+// PackedSet is a read-only view of one of a [PackedSetStore]'s sets.
+type PackedSet[E comparable] struct{ elements []E }
+
+// Len returns the number of elements in the PackedSet.
+func (me PackedSet[E]) Len() int { return len(me.elements) }
+
+// IsEmpty returns true if there are no elements in the PackedSet;
+// otherwise returns false.
+func (me PackedSet[E]) IsEmpty() bool { return len(me.elements) == 0 }
+
+// Contains returns true if element is in the PackedSet; otherwise returns
+// false.
+func (me PackedSet[E]) Contains(element E) bool {
+	return slices.Contains(me.elements, element)
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+func (me PackedSet[E]) All() iter.Seq[E] {
+	return slices.Values(me.elements)
+}
+
+// ToSlice returns a copy of this PackedSet's elements as an unsorted
+// slice.
+func (me PackedSet[E]) ToSlice() []E { return slices.Clone(me.elements) }
+
+// ToSet returns a copy of this PackedSet's elements as a plain Set.
+func (me PackedSet[E]) ToSet() Set[E] { return New(me.elements...) }
+
+// String returns a human readable string representation of the PackedSet.
+func (me PackedSet[E]) String() string {
+	set := me.ToSet()
+	return set.String()
+}
@@ -0,0 +1,90 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPackedSetStore(t *testing.T) {
+	store := NewPackedSetStore[string, int]()
+	store.Put("a", 1, 2, 2, 3)
+	store.Put("b", 9)
+	store.Add("a", 3, 4) // moves a after b
+	store.Add("b", 8, 9)
+	a, ok := store.Get("a")
+	check(sortedStr(a.ToSet()), a.Len(), "{1 2 3 4}", 4, t)
+	b, _ := store.Get("b")
+	check(sortedStr(b.ToSet()), b.Len(), "{8 9}", 2, t)
+	if !ok || !a.Contains(4) || a.Contains(9) || store.Len() != 2 {
+		t.Errorf("unexpected %v %v", a.String(), b.String())
+	}
+	store.Delete("a", 2, 7)
+	a, _ = store.Get("a")
+	check(sortedStr(a.ToSet()), a.Len(), "{1 3 4}", 3, t)
+	store.Put("b")
+	if b, ok := store.Get("b"); !ok || !b.IsEmpty() {
+		t.Error("expected empty b")
+	}
+	if !store.DeleteSet("b") || store.DeleteSet("b") {
+		t.Error("expected b to be deleted once")
+	}
+	if _, ok := store.Get("b"); ok {
+		t.Error("unexpected b")
+	}
+	store.Compact()
+	if len(store.pool) != 3 || store.waste != 0 {
+		t.Errorf("expected compacted pool of 3, got %d", len(store.pool))
+	}
+}
+
+func TestPackedSetStoreMany(t *testing.T) {
+	store := NewPackedSetStore[int, string]()
+	for i := range 10000 {
+		store.Put(i%1000, fmt.Sprint(i), fmt.Sprint(i+1))
+		store.Add(i%1000, fmt.Sprint(i))
+	}
+	if store.Len() != 1000 {
+		t.Errorf("expected 1000, got %d", store.Len())
+	}
+	for id := range store.IDs() {
+		s, _ := store.Get(id)
+		if s.Len() != 2 || !s.Contains(fmt.Sprint(id+9000)) {
+			t.Fatalf("%d: unexpected %v", id, s.String())
+		}
+	}
+	if store.waste > len(store.pool)-store.waste+64 {
+		t.Errorf("expected waste to be reclaimed, got %d of %d",
+			store.waste, len(store.pool))
+	}
+}
+
+func TestPackedSetStoreAllContaining(t *testing.T) {
+	for _, indexed := range []bool{false, true} {
+		store := NewPackedSetStore[int, string]()
+		store.Put(1, "go", "rust")
+		if indexed {
+			store.SetIndexed(true) // built from the existing sets
+		}
+		store.Put(2, "go")
+		store.Add(3, "python", "go")
+		store.Delete(3, "go")
+		store.Put(1, "rust", "zig") // replaces go
+		ids := store.AllContaining("go")
+		check(sortedStr(ids), ids.Len(), "{2}", 1, t)
+		ids = store.AllContaining("rust")
+		check(sortedStr(ids), ids.Len(), "{1}", 1, t)
+		store.DeleteSet(2)
+		if ids := store.AllContaining("go"); !ids.IsEmpty() ||
+			store.Indexed() != indexed {
+			t.Errorf("indexed %t: unexpected %v", indexed, sortedStr(ids))
+		}
+		ids = store.AllContaining("cobol") // never added
+		ids.Add(4)
+		check(sortedStr(ids), ids.Len(), "{4}", 1, t)
+		if indexed && len(store.index) != 3 {
+			t.Errorf("expected 3 indexed elements, got %d",
+				len(store.index))
+		}
+	}
+}
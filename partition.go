@@ -0,0 +1,96 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"slices"
+)
+
+// PartitionRefinement maintains a partition of a fixed universe of
+// elements into disjoint blocks, which it refines (Hopcroft-style) by
+// splitting every block that a splitter set cuts. This is the core of DFA
+// minimization, bisimulation, and similar algorithms. Blocks are
+// identified by index; block 0 initially holds every element.
+type PartitionRefinement[E comparable] struct {
+	blocks  []Set[E]
+	blockOf map[E]int
+}
+
+// PartitionSplit records that Refine split block Old, moving the elements
+// that were in the splitter into the new block New.
+type PartitionSplit struct{ Old, New int }
+
+// NewPartitionRefinement returns a new PartitionRefinement with a single
+// block containing the given elements (if any). If no elements are given,
+// the type must be specified since it can't be inferred.
+func NewPartitionRefinement[E comparable](
+	elements ...E) *PartitionRefinement[E] {
+	block := New(elements...)
+	blockOf := make(map[E]int, block.Len())
+	for element := range block.set {
+		blockOf[element] = 0
+	}
+	return &PartitionRefinement[E]{blocks: []Set[E]{block},
+		blockOf: blockOf}
+}
+
+// Len returns the number of blocks.
+func (me *PartitionRefinement[E]) Len() int { return len(me.blocks) }
+
+// BlockOf returns the index of the block containing element and true;
+// or 0 and false if element isn't in the partition's universe.
+func (me *PartitionRefinement[E]) BlockOf(element E) (int, bool) {
+	i, ok := me.blockOf[element]
+	return i, ok
+}
+
+// BlockLen returns the number of elements in the i-th block.
+func (me *PartitionRefinement[E]) BlockLen(i int) int {
+	return me.blocks[i].Len()
+}
+
+// Block returns an iterator over the i-th block's elements, e.g.,
+// for element := range p.Block(i) ...
+func (me *PartitionRefinement[E]) Block(i int) iter.Seq[E] {
+	return me.blocks[i].All()
+}
+
+// Refine splits every block that contains some but not all of the
+// splitter's elements into the part inside the splitter (which becomes a
+// new block) and the part outside (which keeps the old index), and
+// returns the splits made in ascending order of their old block (so the
+// new blocks are numbered the same however the splitter iterates).
+// Splitter elements that aren't in the partition's universe are ignored.
+// The work done is proportional to the splitter's size, not the
+// universe's, so for Hopcroft's algorithm the caller can requeue just the
+// smaller half of each split.
+func (me *PartitionRefinement[E]) Refine(
+	splitter Interface[E]) []PartitionSplit {
+	touched := make(map[int][]E)
+	var order []int
+	for element := range splitter.All() {
+		if i, ok := me.blockOf[element]; ok {
+			if _, seen := touched[i]; !seen {
+				order = append(order, i)
+			}
+			touched[i] = append(touched[i], element)
+		}
+	}
+	slices.Sort(order)
+	var splits []PartitionSplit
+	for _, i := range order {
+		inside := touched[i]
+		if len(inside) == me.blocks[i].Len() {
+			continue // the splitter covers the whole block
+		}
+		j := len(me.blocks)
+		me.blocks = append(me.blocks, New(inside...))
+		me.blocks[i].Delete(inside...)
+		for _, element := range inside {
+			me.blockOf[element] = j
+		}
+		splits = append(splits, PartitionSplit{Old: i, New: j})
+	}
+	return splits
+}
@@ -0,0 +1,44 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPartitionRefinement(t *testing.T) {
+	p := NewPartitionRefinement(1, 2, 3, 4, 5, 6)
+	evens := New(2, 4, 6, 8)
+	splits := p.Refine(&evens)
+	if len(splits) != 1 || splits[0] != (PartitionSplit{0, 1}) {
+		t.Fatalf("expected one split 0->1, got %v", splits)
+	}
+	if p.Len() != 2 || p.BlockLen(0) != 3 || p.BlockLen(1) != 3 {
+		t.Errorf("unexpected blocks %d %d %d", p.Len(), p.BlockLen(0),
+			p.BlockLen(1))
+	}
+	if _, ok := p.BlockOf(8); ok {
+		t.Error("expected 8 outside the universe")
+	}
+	small := New(1, 2)
+	splits = p.Refine(&small)
+	if !slices.Equal(splits, []PartitionSplit{{0, 2}, {1, 3}}) ||
+		p.Len() != 4 {
+		t.Fatalf("expected splits 0->2 1->3 and 4 blocks, got %v %d",
+			splits, p.Len())
+	}
+	i, _ := p.BlockOf(1)
+	j, _ := p.BlockOf(2)
+	if i == j || p.BlockLen(i) != 1 || p.BlockLen(j) != 1 {
+		t.Errorf("expected 1 and 2 in singleton blocks")
+	}
+	block, _ := p.BlockOf(3)
+	elements := slices.Sorted(p.Block(block))
+	if !slices.Equal(elements, []int{3, 5}) {
+		t.Errorf("expected [3 5], got %v", elements)
+	}
+	whole := New(3, 5)
+	if splits := p.Refine(&whole); len(splits) != 0 {
+		t.Errorf("expected no splits, got %v", splits)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"cmp"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PathSet is a set of filesystem paths with directory semantics, e.g., for
+// ignore lists: adding "/a/b" makes [PathSet.ContainsPrefixOf] true for
+// "/a/b/c.txt" (but not for "/a/bc"). Paths are normalized with
+// filepath.Clean (and, for sets made by [NewAbsPathSet], made absolute)
+// before being stored or looked up.
+type PathSet struct {
+	set  Set[string]
+	base string // if not "", relative paths are joined to this
+}
+
+// NewPathSet returns a new PathSet containing the cleaned forms of the
+// given paths (if any).
+func NewPathSet(paths ...string) PathSet {
+	set := PathSet{set: New[string]()}
+	set.Add(paths...)
+	return set
+}
+
+// NewAbsPathSet returns a new PathSet which makes every path absolute
+// (relative to the current directory when NewAbsPathSet is called) and
+// which contains the given paths (if any). Returns an error if the
+// current directory can't be determined.
+func NewAbsPathSet(paths ...string) (PathSet, error) {
+	base, err := os.Getwd()
+	if err != nil {
+		return PathSet{}, err
+	}
+	set := PathSet{set: New[string](), base: base}
+	set.Add(paths...)
+	return set, nil
+}
+
+// Normalize returns path in the form the PathSet stores it.
+func (me *PathSet) Normalize(path string) string {
+	if me.base != "" && !filepath.IsAbs(path) {
+		return filepath.Join(me.base, path)
+	}
+	return filepath.Clean(path)
+}
+
+// Add adds the normalized forms of the given path(s) to the PathSet.
+func (me *PathSet) Add(paths ...string) {
+	for _, path := range paths {
+		me.set.set[me.Normalize(path)] = struct{}{}
+	}
+}
+
+// Delete deletes the normalized forms of the given path(s) from the
+// PathSet.
+func (me *PathSet) Delete(paths ...string) {
+	for _, path := range paths {
+		me.set.Delete(me.Normalize(path))
+	}
+}
+
+// Clear deletes all the paths in the PathSet.
+func (me *PathSet) Clear() { me.set.Clear() }
+
+// Len returns the number of paths in the PathSet.
+func (me *PathSet) Len() int { return me.set.Len() }
+
+// IsEmpty returns true if there are no paths in the PathSet; otherwise
+// returns false.
+func (me *PathSet) IsEmpty() bool { return me.set.IsEmpty() }
+
+// Contains returns true if path's normalized form is in the PathSet;
+// otherwise returns false.
+func (me *PathSet) Contains(path string) bool {
+	return me.set.Contains(me.Normalize(path))
+}
+
+// ContainsPrefixOf returns true if path or any of the directories it is
+// in is in the PathSet; otherwise returns false.
+func (me *PathSet) ContainsPrefixOf(path string) bool {
+	_, ok := me.PrefixOf(path)
+	return ok
+}
+
+// PrefixOf returns the longest path in the PathSet that is path or one of
+// the directories path is in, and true; or "" and false if there isn't
+// one.
+func (me *PathSet) PrefixOf(path string) (string, bool) {
+	path = me.Normalize(path)
+	for {
+		if me.set.Contains(path) {
+			return path, true
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", false
+		}
+		path = parent
+	}
+}
+
+// All returns an iterator over the paths in path order (i.e., compared
+// component by component, so a directory is immediately followed by its
+// contents), e.g., for path := range pathset.All() ...
+func (me *PathSet) All() iter.Seq[string] {
+	return slices.Values(me.ToSlice())
+}
+
+// ToSlice returns this PathSet's paths as a slice in path order.
+func (me *PathSet) ToSlice() []string {
+	return slices.SortedFunc(me.set.All(), comparePaths)
+}
+
+// String returns a human readable string representation of the PathSet
+// with its paths in path order.
+// The paths are quoted (as in [Set.String]) so that spaces are
+// unambiguous.
+func (me *PathSet) String() string {
+	return sortedString(slices.Values(me.ToSlice()))
+}
+
+func comparePaths(a, b string) int {
+	for a != "" && b != "" {
+		i := strings.IndexRune(a, filepath.Separator)
+		j := strings.IndexRune(b, filepath.Separator)
+		x, y := a, b
+		if i != -1 {
+			x, a = a[:i], a[i+1:]
+		} else {
+			a = ""
+		}
+		if j != -1 {
+			y, b = b[:j], b[j+1:]
+		} else {
+			b = ""
+		}
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
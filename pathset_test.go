@@ -0,0 +1,59 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPathSet(t *testing.T) {
+	p := filepath.FromSlash
+	s := NewPathSet(p("/a/b/"), p("/a/./c/../d"), p("/a-b"))
+	if !s.Contains(p("/a/d")) || s.Len() != 3 {
+		t.Errorf("expected cleaned paths, got %v", s.String())
+	}
+	if !s.ContainsPrefixOf(p("/a/b/c.txt")) ||
+		!s.ContainsPrefixOf(p("/a/b")) || s.ContainsPrefixOf(p("/a/bc")) ||
+		s.ContainsPrefixOf(p("/a")) {
+		t.Error("unexpected prefix membership")
+	}
+	s.Add(p("/a"))
+	if x, ok := s.PrefixOf(p("/a/b/c/d.txt")); !ok || x != p("/a/b") {
+		t.Errorf("expected /a/b, got %q %t", x, ok)
+	}
+	exp := []string{p("/a"), p("/a/b"), p("/a/d"), p("/a-b")}
+	if got := slices.Collect(s.All()); !slices.Equal(got, exp) {
+		t.Errorf("expected %v, got %v", exp, got)
+	}
+	s.Delete(p("/a/b/"))
+	if s.Contains(p("/a/b")) || !s.ContainsPrefixOf(p("/a/b/c.txt")) {
+		t.Error("unexpected membership after delete")
+	}
+	rel := NewPathSet("docs", "./src/")
+	if !rel.ContainsPrefixOf(filepath.Join("src", "main.go")) ||
+		rel.ContainsPrefixOf("main.go") {
+		t.Errorf("unexpected relative membership in %v", rel.String())
+	}
+}
+
+func TestAbsPathSet(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Skip(err)
+	}
+	s, err := NewAbsPathSet("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.ContainsPrefixOf(filepath.Join(wd, "testdata", "x")) ||
+		!s.ContainsPrefixOf(filepath.Join("testdata", "y")) {
+		t.Errorf("expected absolute paths, got %v", s.String())
+	}
+}
+
+func TestPathSetString(t *testing.T) {
+	s := NewPathSet("my docs", "b", "a b")
+	check(s.String(), s.Len(), `{"a b" "b" "my docs"}`, 3, t)
+}
@@ -0,0 +1,188 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// PatternSet is a set of glob patterns (using path.Match syntax), e.g.,
+// for include/exclude rule engines. [PatternSet.Matches] only tries the
+// patterns whose literal prefix (the part before any metacharacter) is a
+// prefix of the name, so large sets of mostly-distinct patterns are cheap
+// to match against. Set algebra works on the patterns themselves.
+// Matching doesn't modify the PatternSet, so it may be done by several
+// goroutines at once as long as none is changing the PatternSet.
+type PatternSet struct {
+	set     Set[string]
+	index   map[string][]string // literal prefix → patterns
+	lengths []int               // distinct literal prefix lengths, sorted
+}
+
+// NewPatternSet returns a new PatternSet containing the given patterns (if
+// any). If any pattern is malformed, the returned PatternSet is empty and
+// an error wrapping [ErrInvalidElement] and path.ErrBadPattern is
+// returned.
+func NewPatternSet(patterns ...string) (PatternSet, error) {
+	set := PatternSet{set: New[string](), index: make(map[string][]string)}
+	err := set.Add(patterns...)
+	return set, err
+}
+
+// Add adds the given pattern(s) to the PatternSet if they are all well
+// formed; otherwise it adds none of them and returns an error for the
+// first malformed one (see [NewPatternSet]).
+func (me *PatternSet) Add(patterns ...string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w %q: %w", ErrInvalidElement, pattern, err)
+		}
+	}
+	if me.index == nil {
+		*me = PatternSet{set: New[string](),
+			index: make(map[string][]string)}
+	}
+	newPrefix := false
+	for _, pattern := range patterns {
+		if !me.set.Contains(pattern) {
+			me.set.set[pattern] = struct{}{}
+			prefix := literalPrefix(pattern)
+			if _, ok := me.index[prefix]; !ok {
+				newPrefix = true
+			}
+			me.index[prefix] = append(me.index[prefix], pattern)
+		}
+	}
+	if newPrefix {
+		me.updateLengths()
+	}
+	return nil
+}
+
+// Delete deletes the given pattern(s) from the PatternSet.
+func (me *PatternSet) Delete(patterns ...string) {
+	lostPrefix := false
+	for _, pattern := range patterns {
+		if !me.set.Contains(pattern) {
+			continue
+		}
+		me.set.Delete(pattern)
+		prefix := literalPrefix(pattern)
+		bucket := slices.DeleteFunc(me.index[prefix], func(p string) bool {
+			return p == pattern
+		})
+		if len(bucket) == 0 {
+			delete(me.index, prefix)
+			lostPrefix = true
+		} else {
+			me.index[prefix] = bucket
+		}
+	}
+	if lostPrefix {
+		me.updateLengths()
+	}
+}
+
+// Clear deletes all the patterns in the PatternSet.
+func (me *PatternSet) Clear() {
+	me.set.Clear()
+	clear(me.index)
+	me.lengths = nil
+}
+
+// Len returns the number of patterns in the PatternSet.
+func (me *PatternSet) Len() int { return me.set.Len() }
+
+// IsEmpty returns true if there are no patterns in the PatternSet;
+// otherwise returns false.
+func (me *PatternSet) IsEmpty() bool { return me.set.IsEmpty() }
+
+// Contains returns true if pattern is in the PatternSet; otherwise returns
+// false. (It compares patterns, it doesn't match them; see
+// [PatternSet.Matches].)
+func (me *PatternSet) Contains(pattern string) bool {
+	return me.set.Contains(pattern)
+}
+
+// Matches returns true if name matches any of the PatternSet's patterns;
+// otherwise returns false.
+func (me *PatternSet) Matches(name string) bool {
+	_, ok := me.Match(name)
+	return ok
+}
+
+// Match returns one of the PatternSet's patterns that name matches (the
+// one with the longest literal prefix) and true; or "" and false if none
+// matches.
+func (me *PatternSet) Match(name string) (string, bool) {
+	for i := len(me.lengths) - 1; i >= 0; i-- {
+		n := me.lengths[i]
+		if n > len(name) {
+			continue
+		}
+		for _, pattern := range me.index[name[:n]] {
+			if ok, _ := path.Match(pattern, name); ok {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// All returns an iterator over the patterns, e.g.,
+// for pattern := range patterns.All() ...
+func (me *PatternSet) All() iter.Seq[string] { return me.set.All() }
+
+// Union returns a new PatternSet that contains the patterns from this
+// PatternSet and from the other one.
+func (me *PatternSet) Union(other *PatternSet) PatternSet {
+	return newPatternSetFrom(me.set.Union(other.set))
+}
+
+// Intersection returns a new PatternSet that contains the patterns this
+// PatternSet has in common with the other one.
+func (me *PatternSet) Intersection(other *PatternSet) PatternSet {
+	return newPatternSetFrom(me.set.Intersection(other.set))
+}
+
+// Difference returns a new PatternSet that contains the patterns in this
+// PatternSet that are not in the other one.
+func (me *PatternSet) Difference(other *PatternSet) PatternSet {
+	return newPatternSetFrom(me.set.Difference(other.set))
+}
+
+// String returns a human readable string representation of the
+// PatternSet.
+func (me *PatternSet) String() string { return me.set.String() }
+
+// updateLengths recomputes the distinct literal prefix lengths after the
+// set of prefixes has changed.
+func (me *PatternSet) updateLengths() {
+	lengths := make(map[int]bool, len(me.index))
+	for prefix := range me.index {
+		lengths[len(prefix)] = true
+	}
+	me.lengths = slices.Sorted(maps.Keys(lengths))
+}
+
+// newPatternSetFrom returns a new PatternSet of the given (already valid)
+// patterns.
+func newPatternSetFrom(patterns Set[string]) PatternSet {
+	set := PatternSet{set: New[string](), index: make(map[string][]string)}
+	set.Add(patterns.ToSlice()...)
+	return set
+}
+
+// literalPrefix returns the part of pattern before its first
+// metacharacter.
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i != -1 {
+		return pattern[:i]
+	}
+	return pattern
+}
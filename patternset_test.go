@@ -0,0 +1,66 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"errors"
+	"path"
+	"testing"
+)
+
+func TestPatternSet(t *testing.T) {
+	s, err := NewPatternSet("*.go", "vendor/*", "docs/*.md", "docs/api/*",
+		"[ab]*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		exp  string
+	}{{"main.go", "*.go"}, {"vendor/x", "vendor/*"},
+		{"docs/api/index.md", "docs/api/*"}, {"docs/intro.md", "docs/*.md"},
+		{"b1.txt", "[ab]*.txt"}, {"c1.txt", ""}, {"vendor", ""}} {
+		pattern, ok := s.Match(c.name)
+		if pattern != c.exp || ok != (c.exp != "") {
+			t.Errorf("%q: expected %q, got %q %t", c.name, c.exp, pattern,
+				ok)
+		}
+	}
+	s.Delete("*.go", "nothing")
+	if s.Matches("main.go") || s.Len() != 4 || !s.Contains("vendor/*") {
+		t.Errorf("unexpected %v", s.String())
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.Matches("vendor/x") {
+		t.Error("unexpected nonempty")
+	}
+}
+
+func TestPatternSetInvalid(t *testing.T) {
+	var s PatternSet
+	err := s.Add("ok*", "bad[")
+	if !errors.Is(err, ErrInvalidElement) ||
+		!errors.Is(err, path.ErrBadPattern) {
+		t.Errorf("expected invalid bad pattern error, got %v", err)
+	}
+	if s.Len() != 0 {
+		t.Error("expected no patterns added")
+	}
+	if err := s.Add("ok*"); err != nil || !s.Matches("okay") {
+		t.Errorf("expected ok* to match okay, got %v", err)
+	}
+}
+
+func TestPatternSetAlgebra(t *testing.T) {
+	a, _ := NewPatternSet("*.go", "*.md", "bin/*")
+	b, _ := NewPatternSet("*.md", "tmp/*")
+	u := a.Union(&b)
+	i := a.Intersection(&b)
+	d := a.Difference(&b)
+	check(sortedStr(u.set), u.Len(),
+		"{\"*.go\" \"*.md\" \"bin/*\" \"tmp/*\"}", 4, t)
+	check(sortedStr(i.set), i.Len(), "{\"*.md\"}", 1, t)
+	check(sortedStr(d.set), d.Len(), "{\"*.go\" \"bin/*\"}", 2, t)
+	if !u.Matches("tmp/x") || d.Matches("x.md") {
+		t.Error("expected derived sets to match")
+	}
+}
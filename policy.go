@@ -0,0 +1,134 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decision is the outcome of evaluating a [Policy].
+type Decision uint8
+
+// The possible Decisions.
+const (
+	Denied Decision = iota
+	Allowed
+)
+
+func (me Decision) String() string {
+	if me == Allowed {
+		return "allowed"
+	}
+	return "denied"
+}
+
+// Policy is an allow/deny policy over strings, each side having rules
+// that are exact values, prefixes, or glob patterns (see [PatternSet]).
+// The precedence is:
+//
+//  1. If any deny rule matches, the value is denied.
+//  2. Otherwise, if any allow rule matches, the value is allowed.
+//  3. Otherwise the Default decision applies (initially Denied).
+//
+// Within each side exact values are checked first, then prefixes (longest
+// first), then patterns; this only affects which rule is reported.
+// Evaluating doesn't modify the Policy, so once its rules are in place it
+// may be used by several goroutines at once.
+type Policy struct {
+	allow   policyRules
+	deny    policyRules
+	Default Decision
+}
+
+type policyRules struct {
+	exact    Set[string]
+	prefixes Set[string]
+	patterns PatternSet
+}
+
+// NewPolicy returns a new Policy with no rules which denies everything by
+// default.
+func NewPolicy() *Policy { return &Policy{} }
+
+// Allow adds exact value allow rules.
+func (me *Policy) Allow(values ...string) { me.allow.addExact(values) }
+
+// AllowPrefix adds allow rules matching values that start with any of the
+// given prefixes.
+func (me *Policy) AllowPrefix(prefixes ...string) {
+	me.allow.addPrefixes(prefixes)
+}
+
+// AllowPattern adds glob pattern allow rules; see [PatternSet.Add].
+func (me *Policy) AllowPattern(patterns ...string) error {
+	return me.allow.patterns.Add(patterns...)
+}
+
+// Deny adds exact value deny rules.
+func (me *Policy) Deny(values ...string) { me.deny.addExact(values) }
+
+// DenyPrefix adds deny rules matching values that start with any of the
+// given prefixes.
+func (me *Policy) DenyPrefix(prefixes ...string) {
+	me.deny.addPrefixes(prefixes)
+}
+
+// DenyPattern adds glob pattern deny rules; see [PatternSet.Add].
+func (me *Policy) DenyPattern(patterns ...string) error {
+	return me.deny.patterns.Add(patterns...)
+}
+
+// Evaluate returns the Policy's decision for value and a human readable
+// reason naming the rule that decided it, e.g., for audit logs.
+func (me *Policy) Evaluate(value string) (Decision, string) {
+	if rule, ok := me.deny.match(value); ok {
+		return Denied, "denied by " + rule
+	}
+	if rule, ok := me.allow.match(value); ok {
+		return Allowed, "allowed by " + rule
+	}
+	return me.Default, me.Default.String() + " by default"
+}
+
+// IsAllowed returns true if the Policy allows value; otherwise returns
+// false.
+func (me *Policy) IsAllowed(value string) bool {
+	decision, _ := me.Evaluate(value)
+	return decision == Allowed
+}
+
+func (me *policyRules) addExact(values []string) {
+	if me.exact.set == nil {
+		me.exact = New[string]()
+	}
+	me.exact.Add(values...)
+}
+
+func (me *policyRules) addPrefixes(prefixes []string) {
+	if me.prefixes.set == nil {
+		me.prefixes = New[string]()
+	}
+	me.prefixes.Add(prefixes...)
+}
+
+// match returns a description of the matching rule and true, or "" and
+// false if no rule matches.
+func (me *policyRules) match(value string) (string, bool) {
+	if me.exact.Contains(value) {
+		return fmt.Sprintf("value %q", value), true
+	}
+	best := -1
+	for prefix := range me.prefixes.set {
+		if len(prefix) > best && strings.HasPrefix(value, prefix) {
+			best = len(prefix)
+		}
+	}
+	if best != -1 {
+		return fmt.Sprintf("prefix %q", value[:best]), true
+	}
+	if pattern, ok := me.patterns.Match(value); ok {
+		return fmt.Sprintf("pattern %q", pattern), true
+	}
+	return "", false
+}
@@ -0,0 +1,70 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPolicy(t *testing.T) {
+	p := NewPolicy()
+	p.AllowPrefix("/api/", "/api/v2/")
+	p.Allow("/health")
+	if err := p.AllowPattern("/static/*.css"); err != nil {
+		t.Fatal(err)
+	}
+	p.DenyPrefix("/api/admin")
+	p.Deny("/api/v2/secret")
+	if err := p.DenyPattern("*.bak", "bad["); err == nil {
+		t.Error("expected bad pattern error")
+	}
+	for _, c := range []struct {
+		value    string
+		decision Decision
+		reason   string
+	}{{"/api/users", Allowed, `allowed by prefix "/api/"`},
+		{"/api/v2/users", Allowed, `allowed by prefix "/api/v2/"`},
+		{"/api/admin/users", Denied, `denied by prefix "/api/admin"`},
+		{"/api/v2/secret", Denied, `denied by value "/api/v2/secret"`},
+		{"/health", Allowed, `allowed by value "/health"`},
+		{"/static/site.css", Allowed,
+			`allowed by pattern "/static/*.css"`},
+		{"/other", Denied, "denied by default"}} {
+		decision, reason := p.Evaluate(c.value)
+		if decision != c.decision || reason != c.reason {
+			t.Errorf("%q: expected %v %q, got %v %q", c.value, c.decision,
+				c.reason, decision, reason)
+		}
+	}
+	p.Default = Allowed
+	if !p.IsAllowed("/other") || p.IsAllowed("/api/admin") {
+		t.Error("unexpected default handling")
+	}
+	var empty Policy
+	if empty.IsAllowed("x") {
+		t.Error("expected zero Policy to deny")
+	}
+}
+
+func TestPolicyConcurrentEvaluate(t *testing.T) {
+	p := NewPolicy()
+	p.AllowPrefix("/api/")
+	if err := p.DenyPattern("*.bak", "/api/private/*"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for range 4 { // run with -race
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				if p.IsAllowed("/api/private/x") ||
+					!p.IsAllowed("/api/public") {
+					t.Error("unexpected decision")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,120 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"slices"
+)
+
+// ExportHashes returns the hashes of the Set's elements (as computed by
+// the given hash function, e.g., a SHA-256 of a canonical encoding) in
+// sorted order with any duplicates removed. The result can be passed to
+// [Commitment] and [ProveMembership].
+func (me *Set[E]) ExportHashes(h func(E) []byte) [][]byte {
+	hashes := make([][]byte, 0, len(me.set))
+	for element := range me.set {
+		hashes = append(hashes, h(element))
+	}
+	slices.SortFunc(hashes, bytes.Compare)
+	return slices.CompactFunc(hashes, bytes.Equal)
+}
+
+// ProofStep is one step of a [MembershipProof]: the hash of a sibling node
+// in the Merkle tree and which side of the path it is on.
+type ProofStep struct {
+	Sibling []byte
+	Left    bool // true if Sibling is the left-hand node
+}
+
+// MembershipProof is a Merkle inclusion proof: the sibling hashes needed
+// to compute a [Commitment] from one of its leaf hashes.
+type MembershipProof []ProofStep
+
+// Commitment returns the SHA-256 Merkle root of the given sorted hashes
+// (see [Set.ExportHashes]). A service can publish this and later prove to
+// clients that particular elements are in the set without publishing the
+// whole set. Leaf and interior node hashes are domain separated, and an
+// unpaired node is promoted unchanged to the next level. The commitment
+// of no hashes is the SHA-256 of nothing.
+func Commitment(hashes [][]byte) []byte {
+	if len(hashes) == 0 {
+		sum := sha256.Sum256(nil)
+		return sum[:]
+	}
+	level := merkleLeaves(hashes)
+	for len(level) > 1 {
+		level = merkleLevel(level)
+	}
+	return level[0]
+}
+
+// ProveMembership returns a proof that leaf is one of the given sorted
+// hashes (see [Set.ExportHashes]) and true; or nil and false if it isn't.
+// See also [VerifyMembershipProof].
+func ProveMembership(hashes [][]byte, leaf []byte) (MembershipProof, bool) {
+	i, found := slices.BinarySearchFunc(hashes, leaf, bytes.Compare)
+	if !found {
+		return nil, false
+	}
+	proof := make(MembershipProof, 0)
+	level := merkleLeaves(hashes)
+	for len(level) > 1 {
+		if i%2 == 1 {
+			proof = append(proof, ProofStep{level[i-1], true})
+		} else if i+1 < len(level) {
+			proof = append(proof, ProofStep{level[i+1], false})
+		}
+		level = merkleLevel(level)
+		i /= 2
+	}
+	return proof, true
+}
+
+// VerifyMembershipProof returns true if the proof shows that leaf (an
+// element's hash) was included in the set with the given commitment;
+// otherwise returns false.
+func VerifyMembershipProof(commitment, leaf []byte,
+	proof MembershipProof) bool {
+	node := merkleHash(0, leaf)
+	for _, step := range proof {
+		if step.Left {
+			node = merkleHash(1, step.Sibling, node)
+		} else {
+			node = merkleHash(1, node, step.Sibling)
+		}
+	}
+	return bytes.Equal(node, commitment)
+}
+
+func merkleLeaves(hashes [][]byte) [][]byte {
+	level := make([][]byte, len(hashes))
+	for i, h := range hashes {
+		level[i] = merkleHash(0, h)
+	}
+	return level
+}
+
+func merkleLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 < len(level) {
+			next = append(next, merkleHash(1, level[i], level[i+1]))
+		} else {
+			next = append(next, level[i])
+		}
+	}
+	return next
+}
+
+// merkleHash returns the SHA-256 of the prefix byte (0 for leaves, 1 for
+// interior nodes) followed by the parts.
+func merkleHash(prefix byte, parts ...[]byte) []byte {
+	hasher := sha256.New()
+	hasher.Write([]byte{prefix})
+	for _, part := range parts {
+		hasher.Write(part)
+	}
+	return hasher.Sum(nil)
+}
@@ -0,0 +1,56 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func sha(s string) []byte {
+	sum := sha256.Sum256([]byte(s))
+	return sum[:]
+}
+
+func TestMembershipProofs(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13} {
+		s := New[string]()
+		for i := range n {
+			s.Add(fmt.Sprintf("element%d", i))
+		}
+		s.Add("element0") // duplicate
+		hashes := s.ExportHashes(sha)
+		if len(hashes) != n || !slices.IsSortedFunc(hashes, bytes.Compare) {
+			t.Fatalf("expected %d sorted hashes, got %d", n, len(hashes))
+		}
+		commitment := Commitment(hashes)
+		for i := range n {
+			leaf := sha(fmt.Sprintf("element%d", i))
+			proof, ok := ProveMembership(hashes, leaf)
+			if !ok {
+				t.Fatalf("expected proof for element%d of %d", i, n)
+			}
+			if !VerifyMembershipProof(commitment, leaf, proof) {
+				t.Errorf("expected proof for element%d of %d to verify", i,
+					n)
+			}
+			if VerifyMembershipProof(commitment, sha("other"), proof) {
+				t.Errorf("expected other leaf not to verify")
+			}
+			if n > 1 {
+				proof[0].Sibling = sha("tampered")
+				if VerifyMembershipProof(commitment, leaf, proof) {
+					t.Errorf("expected tampered proof not to verify")
+				}
+			}
+		}
+		if _, ok := ProveMembership(hashes, sha("missing")); ok {
+			t.Errorf("expected no proof for missing element")
+		}
+	}
+	if !bytes.Equal(Commitment(nil), sha("")) {
+		t.Error("expected empty commitment to be SHA-256 of nothing")
+	}
+}
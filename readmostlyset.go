@@ -0,0 +1,221 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// ReadMostlySet is a set that is safe for concurrent use and is optimized
+// for workloads of mostly Contains calls with rare changes: it is backed
+// by a sync.Map, so goroutines checking membership never contend on a
+// lock. It has the same methods as [SyncSet] apart from [SyncSet.Update]
+// (there is no lock to hold while a multi-step change is made), so either
+// can be swapped for the other (see [ConcurrentSet]) where Update isn't
+// used. Unlike a SyncSet, iteration and the methods that visit every
+// element (e.g., the algebra methods) may or may not see changes made
+// concurrently, and Len is only exact when no changes are in progress. A
+// ReadMostlySet must not be copied after first use.
+type ReadMostlySet[E comparable] struct {
+	set sync.Map // keys are the elements; values are unused
+	n   atomic.Int64
+}
+
+// NewReadMostly returns a new ReadMostlySet containing the given elements
+// (if any). If no elements are given, the type must be specified since it
+// can't be inferred.
+func NewReadMostly[E comparable](elements ...E) *ReadMostlySet[E] {
+	set := &ReadMostlySet[E]{}
+	set.Add(elements...)
+	return set
+}
+
+// Add adds the given element(s) to the ReadMostlySet.
+func (me *ReadMostlySet[E]) Add(elements ...E) {
+	for _, element := range elements {
+		me.AddIfAbsent(element)
+	}
+}
+
+// AddIfAbsent adds element to the ReadMostlySet and returns true if it
+// wasn't already present; otherwise returns false. The check and the add
+// are one atomic step.
+func (me *ReadMostlySet[E]) AddIfAbsent(element E) bool {
+	if _, ok := me.set.Load(element); ok {
+		return false // fast path: no write needed
+	}
+	if _, loaded := me.set.LoadOrStore(element, struct{}{}); loaded {
+		return false
+	}
+	me.n.Add(1)
+	return true
+}
+
+// EnsureWith adds element to the ReadMostlySet and calls onFirst and
+// returns true if it wasn't already present; otherwise returns false. Of
+// several goroutines ensuring the same element exactly one calls onFirst,
+// e.g., so that only the first worker processes an ID. The others don't
+// wait for onFirst to finish.
+func (me *ReadMostlySet[E]) EnsureWith(element E, onFirst func()) bool {
+	if !me.AddIfAbsent(element) {
+		return false
+	}
+	onFirst()
+	return true
+}
+
+// Delete deletes the given element(s) from the ReadMostlySet.
+func (me *ReadMostlySet[E]) Delete(elements ...E) {
+	for _, element := range elements {
+		me.CompareAndDelete(element)
+	}
+}
+
+// CompareAndDelete deletes element from the ReadMostlySet and returns true
+// if it was present; otherwise returns false. The check and the delete
+// are one atomic step.
+func (me *ReadMostlySet[E]) CompareAndDelete(element E) bool {
+	if _, loaded := me.set.LoadAndDelete(element); !loaded {
+		return false
+	}
+	me.n.Add(-1)
+	return true
+}
+
+// Clear deletes all the elements in the ReadMostlySet.
+func (me *ReadMostlySet[E]) Clear() {
+	me.set.Range(func(key, _ any) bool {
+		me.CompareAndDelete(key.(E))
+		return true
+	})
+}
+
+// Len returns the number of elements in the ReadMostlySet.
+func (me *ReadMostlySet[E]) Len() int { return int(me.n.Load()) }
+
+// IsEmpty returns true if there are no elements in the ReadMostlySet;
+// otherwise returns false.
+func (me *ReadMostlySet[E]) IsEmpty() bool { return me.Len() == 0 }
+
+// Contains returns true if element is in the ReadMostlySet; otherwise
+// returns false.
+func (me *ReadMostlySet[E]) Contains(element E) bool {
+	_, ok := me.set.Load(element)
+	return ok
+}
+
+// Difference returns a new Set that contains the elements which are in
+// this ReadMostlySet that are not in the other Set.
+func (me *ReadMostlySet[E]) Difference(other Set[E]) Set[E] {
+	set := me.Clone()
+	return set.Difference(other)
+}
+
+// SymmetricDifference returns a new Set that contains the elements which
+// are in this ReadMostlySet or the other Set—but not in both.
+func (me *ReadMostlySet[E]) SymmetricDifference(other Set[E]) Set[E] {
+	set := me.Clone()
+	return set.SymmetricDifference(other)
+}
+
+// Intersection returns a new Set that contains the elements this
+// ReadMostlySet has in common with the other Set.
+func (me *ReadMostlySet[E]) Intersection(other Set[E]) Set[E] {
+	intersection := New[E]()
+	for element := range other.set {
+		if me.Contains(element) {
+			intersection.set[element] = struct{}{}
+		}
+	}
+	return intersection
+}
+
+// Union returns a new Set that contains the elements from this
+// ReadMostlySet and from the other Set.
+func (me *ReadMostlySet[E]) Union(other Set[E]) Set[E] {
+	set := me.Clone()
+	set.Unite(other)
+	return set
+}
+
+// Unite adds all the elements from other that aren't already in this
+// ReadMostlySet to this ReadMostlySet.
+func (me *ReadMostlySet[E]) Unite(other Set[E]) {
+	for element := range other.set {
+		me.AddIfAbsent(element)
+	}
+}
+
+// Clone returns a copy of this ReadMostlySet's elements as a plain Set.
+func (me *ReadMostlySet[E]) Clone() Set[E] {
+	return NewFromSyncMapKeys[E](&me.set)
+}
+
+// Equal returns true if this ReadMostlySet has the same elements as the
+// other Set; otherwise returns false.
+func (me *ReadMostlySet[E]) Equal(other Set[E]) bool {
+	set := me.Clone()
+	return set.Equal(other)
+}
+
+// IsDisjoint returns true if this ReadMostlySet has no elements in common
+// with the other Set; otherwise returns false.
+func (me *ReadMostlySet[E]) IsDisjoint(other Set[E]) bool {
+	for element := range other.set {
+		if me.Contains(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubsetOf returns true if every member of this ReadMostlySet is in the
+// other Set; otherwise returns false.
+func (me *ReadMostlySet[E]) IsSubsetOf(other Set[E]) bool {
+	for element := range me.All() {
+		if !other.Contains(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSupersetOf returns true if every member of the other Set is in this
+// ReadMostlySet; otherwise returns false.
+func (me *ReadMostlySet[E]) IsSupersetOf(other Set[E]) bool {
+	for element := range other.set {
+		if !me.Contains(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+// The loop body may change the ReadMostlySet; see sync.Map.Range for what
+// the loop sees of concurrent changes.
+func (me *ReadMostlySet[E]) All() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		me.set.Range(func(key, _ any) bool { return yield(key.(E)) })
+	}
+}
+
+// Snapshot returns a FrozenSet of the ReadMostlySet's elements.
+func (me *ReadMostlySet[E]) Snapshot() FrozenSet[E] {
+	return FrozenSet[E]{me.Clone().set}
+}
+
+// ToSlice returns this ReadMostlySet's elements as an unsorted slice.
+func (me *ReadMostlySet[E]) ToSlice() []E {
+	set := me.Clone()
+	return set.ToSlice()
+}
+
+// String returns a human readable string representation of the
+// ReadMostlySet.
+func (me *ReadMostlySet[E]) String() string {
+	set := me.Clone()
+	return set.String()
+}
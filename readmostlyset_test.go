@@ -0,0 +1,71 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestReadMostlySet(t *testing.T) {
+	s := NewReadMostly(1, 2, 3, 3)
+	other := New(3, 4)
+	u := s.Union(other)
+	i := s.Intersection(other)
+	d := s.Difference(other)
+	x := s.SymmetricDifference(other)
+	check(sortedStr(u), u.Len(), "{1 2 3 4}", 4, t)
+	check(sortedStr(i), i.Len(), "{3}", 1, t)
+	check(sortedStr(d), d.Len(), "{1 2}", 2, t)
+	check(sortedStr(x), x.Len(), "{1 2 4}", 3, t)
+	if s.IsDisjoint(other) || !s.IsSupersetOf(New(1, 2)) ||
+		s.IsSubsetOf(other) || !s.Equal(New(3, 2, 1)) ||
+		!s.IsSubsetOf(u) {
+		t.Error("unexpected comparisons")
+	}
+	s.Unite(other)
+	if !s.CompareAndDelete(1) || s.CompareAndDelete(1) ||
+		s.AddIfAbsent(2) {
+		t.Error("unexpected atomic results")
+	}
+	snap := s.Snapshot()
+	check(sortedStr(snap.Thaw()), s.Len(), "{2 3 4}", 3, t)
+	for x := range s.All() {
+		s.Delete(x)
+	}
+	if !s.IsEmpty() || s.String() != "{}" || len(s.ToSlice()) != 0 {
+		t.Error("unexpected nonempty")
+	}
+	s.Add(5, 6)
+	s.Clear()
+	if !s.IsEmpty() || s.Contains(5) {
+		t.Error("unexpected nonempty after Clear")
+	}
+}
+
+func TestReadMostlySetConcurrent(t *testing.T) {
+	var s ReadMostlySet[int]
+	s.Add(1, 2, 3)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				if !s.Contains(i%3 + 1) {
+					t.Error("expected stable members")
+				}
+				if g == 0 && i%100 == 0 {
+					s.Add(100 + i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 13 {
+		t.Errorf("expected 13, got %d", s.Len())
+	}
+}
+
+func TestReadMostlySetEnsureWith(t *testing.T) {
+	testEnsureWith(t, NewReadMostly[int]())
+}
@@ -0,0 +1,202 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"maps"
+	"math/bits"
+	"slices"
+)
+
+// SetRegistry manages a family of named sets over a shared universe of
+// elements, e.g., for label or segment systems with thousands of
+// overlapping sets. Each distinct element is interned once and every
+// named set is a bitmap over the interned elements (at most a bit per
+// element of the universe per set). An inverted index answers "which
+// sets contain e?" without visiting every set, but costs a map entry per
+// element per set containing it, which usually dominates the memory use
+// of well-populated sets. The universe only grows: elements stay interned
+// after being deleted from every set. SetRegistry is not safe for
+// concurrent use.
+type SetRegistry[E comparable] struct {
+	ids      map[E]int
+	universe []E                   // id → element
+	sets     map[string]*bitmapSet // name → member ids
+	index    []Set[string]         // id → names of the sets containing it
+}
+
+type bitmapSet struct {
+	words []uint64
+	n     int
+}
+
+// NewSetRegistry returns a new empty SetRegistry; the type must be
+// specified since it can't be inferred.
+func NewSetRegistry[E comparable]() *SetRegistry[E] {
+	return &SetRegistry[E]{ids: make(map[E]int),
+		sets: make(map[string]*bitmapSet)}
+}
+
+// Len returns the number of named sets.
+func (me *SetRegistry[E]) Len() int { return len(me.sets) }
+
+// UniverseLen returns the number of distinct elements ever added.
+func (me *SetRegistry[E]) UniverseLen() int { return len(me.universe) }
+
+// Names returns the names of the registry's sets in ascending order.
+func (me *SetRegistry[E]) Names() []string {
+	return slices.Sorted(maps.Keys(me.sets))
+}
+
+// Has returns true if the registry has a set with the given name;
+// otherwise returns false.
+func (me *SetRegistry[E]) Has(name string) bool {
+	_, ok := me.sets[name]
+	return ok
+}
+
+// Add adds the given element(s) to the named set, which is created (even
+// if no elements are given) if it doesn't exist.
+func (me *SetRegistry[E]) Add(name string, elements ...E) {
+	bset, ok := me.sets[name]
+	if !ok {
+		bset = &bitmapSet{}
+		me.sets[name] = bset
+	}
+	for _, element := range elements {
+		id := me.intern(element)
+		if bset.add(id) {
+			me.index[id].Add(name)
+		}
+	}
+}
+
+// Delete deletes the given element(s) from the named set (if it exists).
+func (me *SetRegistry[E]) Delete(name string, elements ...E) {
+	bset, ok := me.sets[name]
+	if !ok {
+		return
+	}
+	for _, element := range elements {
+		if id, ok := me.ids[element]; ok && bset.delete(id) {
+			me.index[id].Delete(name)
+		}
+	}
+}
+
+// DeleteSet deletes the named set and returns true; or returns false if
+// there is no such set.
+func (me *SetRegistry[E]) DeleteSet(name string) bool {
+	bset, ok := me.sets[name]
+	if !ok {
+		return false
+	}
+	for id := range bset.all() {
+		me.index[id].Delete(name)
+	}
+	delete(me.sets, name)
+	return true
+}
+
+// Contains returns true if element is in the named set; otherwise returns
+// false.
+func (me *SetRegistry[E]) Contains(name string, element E) bool {
+	bset, ok := me.sets[name]
+	if !ok {
+		return false
+	}
+	id, ok := me.ids[element]
+	return ok && bset.contains(id)
+}
+
+// SetLen returns the number of elements in the named set (0 if there's no
+// such set).
+func (me *SetRegistry[E]) SetLen(name string) int {
+	if bset, ok := me.sets[name]; ok {
+		return bset.n
+	}
+	return 0
+}
+
+// Get returns a copy of the named set and true; or an empty Set and false
+// if there is no such set.
+func (me *SetRegistry[E]) Get(name string) (Set[E], bool) {
+	bset, ok := me.sets[name]
+	if !ok {
+		return New[E](), false
+	}
+	return me.toSet(bset), true
+}
+
+// SetsContaining returns the names of the sets that contain element in
+// ascending order.
+func (me *SetRegistry[E]) SetsContaining(element E) []string {
+	id, ok := me.ids[element]
+	if !ok {
+		return nil
+	}
+	return me.index[id].ToSliceFunc(func(a, b string) bool {
+		return a < b
+	})
+}
+
+func (me *SetRegistry[E]) intern(element E) int {
+	id, ok := me.ids[element]
+	if !ok {
+		id = len(me.universe)
+		me.ids[element] = id
+		me.universe = append(me.universe, element)
+		me.index = append(me.index, New[string]())
+	}
+	return id
+}
+
+func (me *SetRegistry[E]) toSet(bset *bitmapSet) Set[E] {
+	set := Set[E]{set: make(map[E]struct{}, bset.n)}
+	for id := range bset.all() {
+		set.set[me.universe[id]] = struct{}{}
+	}
+	return set
+}
+
+// add sets id's bit and returns true if it wasn't already set.
+func (me *bitmapSet) add(id int) bool {
+	if i := id / 64; i >= len(me.words) {
+		me.words = append(me.words, make([]uint64, i+1-len(me.words))...)
+	}
+	if me.contains(id) {
+		return false
+	}
+	me.words[id/64] |= 1 << (id % 64)
+	me.n++
+	return true
+}
+
+// delete clears id's bit and returns true if it was set.
+func (me *bitmapSet) delete(id int) bool {
+	if !me.contains(id) {
+		return false
+	}
+	me.words[id/64] &^= 1 << (id % 64)
+	me.n--
+	return true
+}
+
+func (me *bitmapSet) contains(id int) bool {
+	return id/64 < len(me.words) && me.words[id/64]&(1<<(id%64)) != 0
+}
+
+func (me *bitmapSet) all() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, word := range me.words {
+			for word != 0 {
+				j := bits.TrailingZeros64(word)
+				word &= word - 1
+				if !yield(i*64 + j) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSetRegistry(t *testing.T) {
+	r := NewSetRegistry[string]()
+	r.Add("admins", "ann", "bob")
+	r.Add("staff", "ann", "bob", "cat", "ann")
+	r.Add("empty")
+	if r.Len() != 3 || r.UniverseLen() != 3 {
+		t.Errorf("expected 3 sets over 3 elements, got %d %d", r.Len(),
+			r.UniverseLen())
+	}
+	if fmt.Sprint(r.Names()) != "[admins empty staff]" {
+		t.Errorf("unexpected names %v", r.Names())
+	}
+	if fmt.Sprint(r.SetsContaining("ann")) != "[admins staff]" {
+		t.Errorf("unexpected %v", r.SetsContaining("ann"))
+	}
+	if r.SetsContaining("zed") != nil {
+		t.Error("expected no sets for unknown element")
+	}
+	if !r.Contains("staff", "cat") || r.Contains("admins", "cat") ||
+		r.Contains("nope", "ann") {
+		t.Error("unexpected membership")
+	}
+	r.Delete("staff", "ann", "zed")
+	if r.SetLen("staff") != 2 ||
+		fmt.Sprint(r.SetsContaining("ann")) != "[admins]" {
+		t.Errorf("unexpected staff %d %v", r.SetLen("staff"),
+			r.SetsContaining("ann"))
+	}
+	s, ok := r.Get("staff")
+	check(sortedStr(s), s.Len(), "{\"bob\" \"cat\"}", 2, t)
+	if !ok {
+		t.Error("expected staff")
+	}
+	if !r.DeleteSet("admins") || r.DeleteSet("admins") || r.Has("admins") {
+		t.Error("expected admins to be deleted once")
+	}
+	if len(r.SetsContaining("ann")) != 0 || r.UniverseLen() != 3 {
+		t.Errorf("unexpected %v %d", r.SetsContaining("ann"),
+			r.UniverseLen())
+	}
+}
+
+func TestSetRegistryLarge(t *testing.T) {
+	r := NewSetRegistry[int]()
+	for i := range 1000 {
+		r.Add(fmt.Sprintf("mod%d", i%7), i)
+	}
+	if r.SetLen("mod3") != 143 || !r.Contains("mod3", 997) {
+		t.Errorf("unexpected mod3 %d", r.SetLen("mod3"))
+	}
+	if fmt.Sprint(r.SetsContaining(700)) != "[mod0]" {
+		t.Errorf("unexpected %v", r.SetsContaining(700))
+	}
+}
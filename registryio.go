@@ -0,0 +1,253 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const setRegistryMagic = "SRG1"
+
+// Save writes the whole registry to w using the given codec for the
+// elements. The format is a magic number, then a manifest (the number of
+// sets followed by each set's name and length, in name order), then the
+// universe (its size then each element's length and bytes), then each
+// set's bitmap (its number of words then each word), all numbers being
+// uvarints. See also [LoadSetRegistry] and [SetRegistry.SaveFile].
+func (me *SetRegistry[E]) Save(w io.Writer, codec Codec[E]) error {
+	out := bufio.NewWriter(w)
+	buf := append([]byte(nil), setRegistryMagic...)
+	names := me.Names()
+	buf = binary.AppendUvarint(buf, uint64(len(names)))
+	for _, name := range names {
+		buf = binary.AppendUvarint(buf, uint64(len(name)))
+		buf = append(buf, name...)
+		buf = binary.AppendUvarint(buf, uint64(me.sets[name].n))
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(me.universe)))
+	if _, err := out.Write(buf); err != nil {
+		return err
+	}
+	for _, element := range me.universe {
+		raw, err := codec.Encode(element)
+		if err != nil {
+			return err
+		}
+		buf = binary.AppendUvarint(buf[:0], uint64(len(raw)))
+		if _, err := out.Write(append(buf, raw...)); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		words := me.sets[name].words
+		buf = binary.AppendUvarint(buf[:0], uint64(len(words)))
+		for _, word := range words {
+			buf = binary.AppendUvarint(buf, word)
+		}
+		if _, err := out.Write(buf); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// SaveFile saves the registry (see [SetRegistry.Save]) to the named file
+// atomically: it writes to a temporary file in the same directory and
+// then renames it, so readers never see a partly written file.
+func (me *SetRegistry[E]) SaveFile(filename string, codec Codec[E]) error {
+	return writeFileAtomic(filename, true, func(w io.Writer) error {
+		return me.Save(w, codec)
+	})
+}
+
+// LoadSetRegistry returns a new SetRegistry read from r using the given
+// codec. The data must be in the format written by [SetRegistry.Save].
+// Failures are returned as a [*ParseError].
+func LoadSetRegistry[E comparable](r io.Reader,
+	codec Codec[E]) (*SetRegistry[E], error) {
+	in, ok := r.(byteReader)
+	if !ok {
+		in = bufio.NewReader(r)
+	}
+	counter := &countingReader{in: in}
+	registry := NewSetRegistry[E]()
+	magic := make([]byte, len(setRegistryMagic))
+	if _, err := io.ReadFull(counter, magic); err != nil ||
+		string(magic) != setRegistryMagic {
+		return registry, &ParseError{0,
+			errors.New("not a set registry")}
+	}
+	var raw bytes.Buffer
+	readBytes := func() ([]byte, error) {
+		size, err := binary.ReadUvarint(counter)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		// Don't trust size for allocating: the data may be corrupt.
+		raw.Reset()
+		if _, err := io.CopyN(&raw, counter, int64(min(size,
+			math.MaxInt64))); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return raw.Bytes(), nil
+	}
+	offset := counter.offset
+	count, err := binary.ReadUvarint(counter)
+	if err != nil {
+		return registry, &ParseError{offset, unexpectedEOF(err)}
+	}
+	type entry struct {
+		name string
+		n    uint64
+	}
+	var manifest []entry
+	for range count {
+		offset = counter.offset
+		name, err := readBytes()
+		if err != nil {
+			return registry, &ParseError{offset, err}
+		}
+		n, err := binary.ReadUvarint(counter)
+		if err != nil {
+			return registry, &ParseError{offset, unexpectedEOF(err)}
+		}
+		if registry.Has(string(name)) {
+			return registry, &ParseError{offset,
+				fmt.Errorf("duplicate set %q", name)}
+		}
+		registry.Add(string(name))
+		manifest = append(manifest, entry{string(name), n})
+	}
+	offset = counter.offset
+	size, err := binary.ReadUvarint(counter)
+	if err != nil {
+		return registry, &ParseError{offset, unexpectedEOF(err)}
+	}
+	for range size {
+		offset = counter.offset
+		raw, err := readBytes()
+		if err != nil {
+			return registry, &ParseError{offset, err}
+		}
+		element, err := codec.Decode(raw)
+		if err != nil {
+			return registry, &ParseError{offset, err}
+		}
+		if _, ok := registry.ids[element]; ok {
+			return registry, &ParseError{offset,
+				fmt.Errorf("%w: %v", ErrElementExists, element)}
+		}
+		registry.intern(element)
+	}
+	maxWords := uint64(len(registry.universe)+63) / 64
+	for _, entry := range manifest {
+		offset = counter.offset
+		n, err := binary.ReadUvarint(counter)
+		if err != nil {
+			return registry, &ParseError{offset, unexpectedEOF(err)}
+		}
+		if n > maxWords {
+			return registry, &ParseError{offset,
+				fmt.Errorf("set %q is bigger than the universe", entry.name)}
+		}
+		bset := registry.sets[entry.name]
+		bset.words = make([]uint64, n)
+		for i := range bset.words {
+			if bset.words[i], err = binary.ReadUvarint(counter); err != nil {
+				return registry, &ParseError{offset, unexpectedEOF(err)}
+			}
+			bset.n += bits.OnesCount64(bset.words[i])
+		}
+		if extra := len(registry.universe) % 64; n == maxWords &&
+			extra != 0 && bset.words[n-1]>>extra != 0 {
+			return registry, &ParseError{offset,
+				fmt.Errorf("set %q is bigger than the universe", entry.name)}
+		}
+		if uint64(bset.n) != entry.n {
+			return registry, &ParseError{offset,
+				fmt.Errorf("set %q has %d elements, manifest says %d",
+					entry.name, bset.n, entry.n)}
+		}
+		for id := range bset.all() {
+			registry.index[id].Add(entry.name)
+		}
+	}
+	return registry, nil
+}
+
+// LoadSetRegistryFile returns a new SetRegistry read from the named file
+// (see [LoadSetRegistry]).
+func LoadSetRegistryFile[E comparable](filename string,
+	codec Codec[E]) (*SetRegistry[E], error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadSetRegistry(file, codec)
+}
+
+// writeFileAtomic writes a file using write by writing a temporary file in
+// the same directory and renaming it, so readers never see a partly
+// written file. The file keeps the permissions of the one it replaces (or
+// gets 0644 if new). If fsync is true the temporary file is synced before
+// the rename and the directory after it.
+func writeFileAtomic(filename string, fsync bool,
+	write func(io.Writer) error) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+	dir := filepath.Dir(filename)
+	file, err := os.CreateTemp(dir, filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // fails harmlessly after the rename
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return err
+	}
+	if fsync {
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), filename); err != nil || !fsync {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir syncs the named directory, so that a rename in it survives power
+// loss. Windows can't sync a directory, so there it does nothing (the
+// file itself was synced before the rename).
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	return errors.Join(err, file.Close())
+}
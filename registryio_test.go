@@ -0,0 +1,81 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetRegistrySaveLoad(t *testing.T) {
+	r := newSegmentRegistry()
+	r.Delete("even", 0) // leaves 0 in the universe but in fewer sets
+	var buf bytes.Buffer
+	if err := r.Save(&buf, IntCodec[int]()); err != nil {
+		t.Fatal(err)
+	}
+	q, err := LoadSetRegistry(bytes.NewReader(buf.Bytes()), IntCodec[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(q.Names()) != fmt.Sprint(r.Names()) ||
+		q.UniverseLen() != r.UniverseLen() {
+		t.Errorf("expected %v, got %v", r.Names(), q.Names())
+	}
+	for _, name := range r.Names() {
+		a, _ := r.Get(name)
+		b, _ := q.Get(name)
+		if !a.Equal(b) {
+			t.Errorf("%s: expected %v, got %v", name, sortedStr(a),
+				sortedStr(b))
+		}
+	}
+	if fmt.Sprint(q.SetsContaining(0)) != "[small-ones three]" {
+		t.Errorf("unexpected %v", q.SetsContaining(0))
+	}
+	data := buf.Bytes()
+	for _, n := range []int{0, 3, 10, len(data) / 2, len(data) - 1} {
+		_, err := LoadSetRegistry(bytes.NewReader(data[:n]),
+			IntCodec[int]())
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%d bytes: expected *ParseError, got %v", n, err)
+		}
+	}
+}
+
+func TestSetRegistryFile(t *testing.T) {
+	r := NewSetRegistry[string]()
+	r.Add("a", "x", "y")
+	r.Add("b")
+	filename := filepath.Join(t.TempDir(), "registry.bin")
+	if err := r.SaveFile(filename, StringCodec[string]()); err != nil {
+		t.Fatal(err)
+	}
+	q, err := LoadSetRegistryFile(filename, StringCodec[string]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.Contains("a", "y") || !q.Has("b") || q.SetLen("b") != 0 {
+		t.Errorf("unexpected %v", q.Names())
+	}
+	for _, mode := range []os.FileMode{0o644, 0o640} {
+		if mode != 0o644 { // a new file is 0644; a replaced one keeps its
+			os.Chmod(filename, mode)
+			if err := r.SaveFile(filename, StringCodec[string]()); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if info, err := os.Stat(filename); err != nil ||
+			info.Mode().Perm() != mode {
+			t.Errorf("expected mode %v, got %v %v", mode, info, err)
+		}
+	}
+	if _, err := LoadSetRegistryFile(filename+".none",
+		StringCodec[string]()); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
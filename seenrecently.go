@@ -0,0 +1,121 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "time"
+
+// SeenRecently tracks which keys have been seen within a sliding time
+// window and approximately how often, e.g., for rate limiting ("has this
+// key been seen more than n times in the last minute?"). The window is
+// divided into buckets which expire one at a time, so counts are
+// approximate in that occurrences expire up to one bucket's width late.
+// Memory use is proportional to the number of distinct keys seen per
+// bucket across the window. SeenRecently is not safe for concurrent use.
+type SeenRecently[E comparable] struct {
+	buckets []map[E]int // ring buffer of per-bucket counts
+	current int         // index of the newest bucket
+	width   time.Duration
+	start   time.Time // when the newest bucket started
+	now     func() time.Time
+}
+
+// NewSeenRecently returns a new SeenRecently with the given window divided
+// into the given number of buckets (at least 1). More buckets give more
+// accurate expiry at the cost of more memory and slower counting.
+func NewSeenRecently[E comparable](window time.Duration,
+	buckets int) *SeenRecently[E] {
+	buckets = max(1, buckets)
+	seen := &SeenRecently[E]{buckets: make([]map[E]int, buckets),
+		width: max(1, window/time.Duration(buckets)), now: time.Now}
+	for i := range seen.buckets {
+		seen.buckets[i] = make(map[E]int)
+	}
+	seen.start = seen.now()
+	return seen
+}
+
+// Window returns the SeenRecently's window (rounded down to a multiple of
+// the number of buckets).
+func (me *SeenRecently[E]) Window() time.Duration {
+	return me.width * time.Duration(len(me.buckets))
+}
+
+// Record records an occurrence of key now and returns how many times key
+// has been seen within the window, including this time.
+func (me *SeenRecently[E]) Record(key E) int {
+	me.advance()
+	me.buckets[me.current][key]++
+	return me.count(key)
+}
+
+// Count returns how many times key has been seen within the window.
+func (me *SeenRecently[E]) Count(key E) int {
+	me.advance()
+	return me.count(key)
+}
+
+// SeenMoreThan returns true if key has been seen more than n times within
+// the window; otherwise returns false.
+func (me *SeenRecently[E]) SeenMoreThan(key E, n int) bool {
+	return me.Count(key) > n
+}
+
+// Contains returns true if key has been seen within the window; otherwise
+// returns false.
+func (me *SeenRecently[E]) Contains(key E) bool {
+	me.advance()
+	for _, bucket := range me.buckets {
+		if _, ok := bucket[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Keys returns a new Set of the keys seen within the window.
+func (me *SeenRecently[E]) Keys() Set[E] {
+	me.advance()
+	keys := New[E]()
+	for _, bucket := range me.buckets {
+		for key := range bucket {
+			keys.set[key] = struct{}{}
+		}
+	}
+	return keys
+}
+
+// Clear forgets all the keys seen.
+func (me *SeenRecently[E]) Clear() {
+	for _, bucket := range me.buckets {
+		clear(bucket)
+	}
+	me.start = me.now()
+}
+
+func (me *SeenRecently[E]) count(key E) int {
+	count := 0
+	for _, bucket := range me.buckets {
+		count += bucket[key]
+	}
+	return count
+}
+
+// advance expires the buckets that have fallen out of the window.
+func (me *SeenRecently[E]) advance() {
+	elapsed := me.now().Sub(me.start)
+	if elapsed < me.width {
+		return
+	}
+	steps := elapsed / me.width
+	if steps >= time.Duration(len(me.buckets)) {
+		for _, bucket := range me.buckets {
+			clear(bucket)
+		}
+	} else {
+		for range steps {
+			me.current = (me.current + 1) % len(me.buckets)
+			clear(me.buckets[me.current])
+		}
+	}
+	me.start = me.start.Add(steps * me.width)
+}
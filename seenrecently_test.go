@@ -0,0 +1,47 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeenRecently(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	seen := NewSeenRecently[string](time.Minute, 6)
+	seen.now = func() time.Time { return clock }
+	seen.start = clock
+	if seen.Window() != time.Minute {
+		t.Errorf("expected 1m window, got %v", seen.Window())
+	}
+	for i := range 5 {
+		if n := seen.Record("a"); n != i+1 {
+			t.Errorf("expected count %d, got %d", i+1, n)
+		}
+		clock = clock.Add(10 * time.Second)
+	}
+	seen.Record("b")
+	if !seen.SeenMoreThan("a", 4) || seen.SeenMoreThan("a", 5) {
+		t.Errorf("expected a seen 5 times, got %d", seen.Count("a"))
+	}
+	keys := seen.Keys()
+	check(sortedStr(keys), keys.Len(), `{"a" "b"}`, 2, t)
+	clock = clock.Add(10 * time.Second) // the first "a" expires
+	if n := seen.Count("a"); n != 4 {
+		t.Errorf("expected count 4, got %d", n)
+	}
+	clock = clock.Add(30 * time.Second)
+	if n := seen.Count("a"); n != 1 || !seen.Contains("b") {
+		t.Errorf("expected count 1 and b, got %d", n)
+	}
+	clock = clock.Add(time.Hour)
+	keys = seen.Keys()
+	if seen.Contains("a") || seen.Contains("b") || !keys.IsEmpty() {
+		t.Error("expected everything to expire")
+	}
+	seen.Record("c")
+	seen.Clear()
+	if seen.Contains("c") {
+		t.Error("expected c to be cleared")
+	}
+}
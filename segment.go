@@ -0,0 +1,208 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Eval evaluates a boolean expression over the registry's named sets and
+// returns the resulting Set, e.g., r.Eval("segmentA AND NOT segmentB").
+// The operators are (case-insensitive) NOT, AND, and OR, in descending
+// order of precedence, and parentheses may be used for grouping. A set
+// name is either a bare word of letters, digits, and any of "_-.:/", or
+// a Go double-quoted string. NOT is relative to the registry's universe.
+// Evaluation works on whole bitmap words, so it is far faster than the
+// equivalent hash-set algebra. Syntax errors are returned as a
+// *[ParseError] whose Offset is a byte offset into expr; an unknown name
+// gives an error wrapping [ErrNotFound].
+func (me *SetRegistry[E]) Eval(expr string) (Set[E], error) {
+	words, err := me.evalWords(expr)
+	if err != nil {
+		return New[E](), err
+	}
+	return me.toSet(&bitmapSet{words: words, n: popCount(words)}), nil
+}
+
+// Count returns the number of elements that [SetRegistry.Eval] would
+// return for expr without building the Set.
+func (me *SetRegistry[E]) Count(expr string) (int, error) {
+	words, err := me.evalWords(expr)
+	if err != nil {
+		return 0, err
+	}
+	return popCount(words), nil
+}
+
+func (me *SetRegistry[E]) evalWords(expr string) ([]uint64, error) {
+	parser := segmentParser[E]{registry: me, expr: expr,
+		size: (len(me.universe) + 63) / 64}
+	words, err := parser.or()
+	if err == nil && parser.peek() != "" {
+		err = parser.errorf("unexpected %q", parser.peek())
+	}
+	return words, err
+}
+
+func popCount(words []uint64) int {
+	n := 0
+	for _, word := range words {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// segmentParser is a recursive descent parser that evaluates as it goes.
+type segmentParser[E comparable] struct {
+	registry *SetRegistry[E]
+	expr     string
+	pos      int
+	size     int // words per bitmap
+}
+
+func (me *segmentParser[E]) or() ([]uint64, error) {
+	words, err := me.and()
+	for err == nil && me.keyword("OR") {
+		var rhs []uint64
+		if rhs, err = me.and(); err == nil {
+			for i := range words {
+				words[i] |= rhs[i]
+			}
+		}
+	}
+	return words, err
+}
+
+func (me *segmentParser[E]) and() ([]uint64, error) {
+	words, err := me.not()
+	for err == nil && me.keyword("AND") {
+		var rhs []uint64
+		if rhs, err = me.not(); err == nil {
+			for i := range words {
+				words[i] &= rhs[i]
+			}
+		}
+	}
+	return words, err
+}
+
+func (me *segmentParser[E]) not() ([]uint64, error) {
+	if !me.keyword("NOT") {
+		return me.primary()
+	}
+	words, err := me.not()
+	if err != nil {
+		return nil, err
+	}
+	for i := range words {
+		words[i] = ^words[i]
+	}
+	if extra := len(me.registry.universe) % 64; extra != 0 {
+		words[len(words)-1] &= 1<<extra - 1 // outside the universe
+	}
+	return words, nil
+}
+
+func (me *segmentParser[E]) primary() ([]uint64, error) {
+	token := me.peek()
+	start := me.pos
+	switch {
+	case token == "":
+		return nil, me.errorf("unexpected end of expression")
+	case token == "(":
+		me.pos += len(token)
+		words, err := me.or()
+		if err != nil {
+			return nil, err
+		}
+		if me.peek() != ")" {
+			return nil, me.errorf("expected \")\"")
+		}
+		me.pos++
+		return words, nil
+	case token == ")" || isSegmentKeyword(token) ||
+		(token[0] != '"' && strings.IndexFunc(token, notNameRune) != -1):
+		return nil, me.errorf("unexpected %q", token)
+	}
+	me.pos += len(token)
+	name := token
+	if token[0] == '"' {
+		var err error
+		if name, err = strconv.Unquote(token); err != nil {
+			return nil, &ParseError{int64(start), err}
+		}
+	}
+	bset, ok := me.registry.sets[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: no set named %q", ErrNotFound, name)
+	}
+	words := make([]uint64, me.size)
+	copy(words, bset.words)
+	return words, nil
+}
+
+// keyword consumes the next token and returns true if it is the given
+// keyword; otherwise it consumes nothing and returns false.
+func (me *segmentParser[E]) keyword(keyword string) bool {
+	if token := me.peek(); strings.EqualFold(token, keyword) {
+		me.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// peek skips whitespace and returns the next token without consuming it,
+// or "" at the end of the expression.
+func (me *segmentParser[E]) peek() string {
+	rest := strings.TrimLeftFunc(me.expr[me.pos:], unicode.IsSpace)
+	me.pos = len(me.expr) - len(rest)
+	if rest == "" {
+		return ""
+	}
+	switch rest[0] {
+	case '(', ')':
+		return rest[:1]
+	case '"':
+		for i := 1; i < len(rest); i++ {
+			switch rest[i] {
+			case '\\':
+				i++
+			case '"':
+				return rest[:i+1]
+			}
+		}
+		return rest // unterminated; Unquote will report it
+	}
+	switch end := strings.IndexFunc(rest, notNameRune); end {
+	case -1:
+		return rest
+	case 0:
+		_, size := utf8.DecodeRuneInString(rest)
+		return rest[:size] // not valid; primary will report it
+	default:
+		return rest[:end]
+	}
+}
+
+func (me *segmentParser[E]) errorf(format string, args ...any) error {
+	return &ParseError{int64(me.pos), fmt.Errorf(format, args...)}
+}
+
+func notNameRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) &&
+		!strings.ContainsRune("_-.:/", r)
+}
+
+func isSegmentKeyword(token string) bool {
+	for _, keyword := range []string{"AND", "OR", "NOT"} {
+		if strings.EqualFold(token, keyword) {
+			return true
+		}
+	}
+	return false
+}
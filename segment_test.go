@@ -0,0 +1,65 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+func newSegmentRegistry() *SetRegistry[int] {
+	r := NewSetRegistry[int]()
+	for i := range 100 {
+		if i%2 == 0 {
+			r.Add("even", i)
+		}
+		if i%3 == 0 {
+			r.Add("three", i)
+		}
+		if i < 10 {
+			r.Add("small-ones", i)
+		}
+	}
+	r.Add("with space", 1, 2)
+	return r
+}
+
+func TestSegmentEval(t *testing.T) {
+	r := newSegmentRegistry() // the universe has 70 elements
+	for _, c := range []struct {
+		expr string
+		exp  int
+	}{{"even", 50}, {"even AND three", 17}, {"even and not three", 33},
+		{"NOT even", 20}, {"not not even", 50}, {"even OR three", 67},
+		{"small-ones AND (even OR three)", 7},
+		{"small-ones AND even OR three", 37},
+		{`"with space" AND NOT even`, 1}, {"NOT (even OR NOT even)", 0}} {
+		n, err := r.Count(c.expr)
+		if err != nil || n != c.exp {
+			t.Errorf("%q: expected %d, got %d %v", c.expr, c.exp, n, err)
+		}
+	}
+	s, err := r.Eval("small-ones AND NOT (even OR three)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(s), s.Len(), "{1 5 7}", 3, t)
+}
+
+func TestSegmentEvalErrors(t *testing.T) {
+	r := newSegmentRegistry()
+	for _, c := range []struct {
+		expr   string
+		offset int64
+	}{{"", 0}, {"even AND", 8}, {"(even", 5}, {"even three", 5},
+		{"even & three", 5}, {"AND even", 0}, {`"open`, 0}, {")", 0}} {
+		_, err := r.Eval(c.expr)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Offset != c.offset {
+			t.Errorf("%q: expected parse error at %d, got %v", c.expr,
+				c.offset, err)
+		}
+	}
+	if _, err := r.Eval("even OR missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -3,6 +3,24 @@
 // ([TOC]) This package provides a generic unordered set implementation
 // (using a map under the hood).
 //
+// Methods and functions that return a single element which may not exist
+// (e.g., Find, Get, Min) return the element and true, or the zero value
+// and false; they never panic.
+//
+// Every function and method that returns a Set (e.g., New, Clone, Union,
+// Intersection, Difference, ExtractFunc) returns one with its own newly
+// made storage, so changing the result never affects the operands or vice
+// versa. But a Set value is a reference to its storage, so assigning or
+// passing a Set by value (e.g., b := a) doesn't copy its elements; use
+// Clone for an independent copy. The other set types behave the same way.
+// The exceptions are the methods that exist to release memory—Reset,
+// Compact, and automatic shrinking (see SetAutoShrink)—which give the Set
+// a new map, after which copies made earlier no longer see its changes.
+//
+// Methods have pointer receivers, apart from the marshaling methods (so
+// that values marshal correctly even when not addressable) and those of
+// small value types such as [PackedSet] views.
+//
 // [TOC]: file:///home/mark/app/golib/doc/index.html
 package set
 
@@ -10,16 +28,28 @@ import (
 	"fmt"
 	"iter"
 	"maps"
+	"reflect"
+	"slices"
 	"strings"
 )
 
-type Set[E comparable] struct{ set map[E]struct{} }
+type Set[E comparable] struct {
+	set        map[E]struct{}
+	peak       int // largest known length since the map was (re)made
+	autoShrink bool
+}
+
+// Integer is a constraint that permits any integer type.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 |
+		~uint32 | ~uint64 | ~uintptr
+}
 
 // New returns a new Set containing the given elements (if any).
 // If no elements are given, the type must be specified since it can't be
 // inferred.
 func New[E comparable](elements ...E) Set[E] {
-	set := Set[E]{make(map[E]struct{}, len(elements))}
+	set := Set[E]{set: make(map[E]struct{}, len(elements))}
 	if len(elements) > 0 {
 		set.Add(elements...)
 	}
@@ -33,15 +63,156 @@ func (me *Set[E]) Add(elements ...E) {
 	}
 }
 
+// LoadStats reports the outcome of a bulk ingestion; see
+// [Set.AddSeqWithStats].
+type LoadStats struct {
+	Seen       int // total number of elements seen
+	Duplicates int // elements skipped because they were already present
+	Added      int // elements that were new to the Set
+}
+
+// DedupRatio returns the proportion of the seen elements that were
+// duplicates, or 0.0 if no elements were seen.
+func (me LoadStats) DedupRatio() float64 {
+	if me.Seen == 0 {
+		return 0.0
+	}
+	return float64(me.Duplicates) / float64(me.Seen)
+}
+
+// AddSeqWithStats adds every element from the given sequence to the Set
+// and returns how many were seen, how many were duplicates (i.e., already
+// in the Set or repeated in the sequence), and how many were new.
+func (me *Set[E]) AddSeqWithStats(seq iter.Seq[E]) LoadStats {
+	var stats LoadStats
+	for element := range seq {
+		stats.Seen++
+		if _, ok := me.set[element]; ok {
+			stats.Duplicates++
+		} else {
+			me.set[element] = struct{}{}
+			stats.Added++
+		}
+	}
+	return stats
+}
+
 // Delete deletes the given element(s) from the Set.
+// See also [Set.SetAutoShrink].
 func (me *Set[E]) Delete(elements ...E) {
+	me.peak = max(me.peak, len(me.set))
 	for _, element := range elements {
 		delete(me.set, element)
 	}
+	me.maybeShrink()
 }
 
-// Clear deletes all the elements in the Set.
-func (me *Set[E]) Clear() { clear(me.set) }
+// ExtractFunc deletes the elements for which pred returns true from the
+// Set and returns them as a new Set, in a single pass.
+// See also [Set.Find] and [Set.SetAutoShrink].
+func (me *Set[E]) ExtractFunc(pred func(E) bool) Set[E] {
+	me.peak = max(me.peak, len(me.set))
+	extracted := New[E]()
+	for element := range me.set {
+		if pred(element) {
+			extracted.set[element] = struct{}{}
+			delete(me.set, element)
+		}
+	}
+	me.maybeShrink()
+	return extracted
+}
+
+// MoveTo deletes the elements for which pred returns true from this Set
+// and adds them to dst in a single pass, e.g., to move work items from a
+// pending Set to an in-flight one. Returns how many elements were moved.
+// (Moving a Set's elements to itself, or to a copy sharing its storage,
+// does nothing.) See also [Set.ExtractFunc].
+func (me *Set[E]) MoveTo(dst *Set[E], pred func(E) bool) int {
+	if sameMap(me.set, dst.set) {
+		return 0
+	}
+	me.peak = max(me.peak, len(me.set))
+	moved := 0
+	for element := range me.set {
+		if pred(element) {
+			dst.set[element] = struct{}{}
+			delete(me.set, element)
+			moved++
+		}
+	}
+	me.maybeShrink()
+	return moved
+}
+
+// ReplaceFunc replaces every element in the Set with f(element), e.g., to
+// lowercase every member of a set of strings. Elements that f maps to the
+// same value are merged, and ReplaceFunc returns how many were lost that
+// way (i.e., how much shorter the Set became). The Set keeps its map, so
+// copies sharing it see the replacements.
+func (me *Set[E]) ReplaceFunc(f func(E) E) int {
+	elements := slices.Collect(maps.Keys(me.set))
+	me.peak = max(me.peak, len(elements))
+	clear(me.set)
+	for _, element := range elements {
+		me.set[f(element)] = struct{}{}
+	}
+	return len(elements) - len(me.set)
+}
+
+// Clear deletes all the elements in the Set but keeps the memory they used
+// (unless auto-shrink is on), so refilling the Set is fast.
+// See also [Set.Reset] and [Set.SetAutoShrink].
+func (me *Set[E]) Clear() {
+	me.peak = max(me.peak, len(me.set))
+	clear(me.set)
+	me.maybeShrink()
+}
+
+// Reset deletes all the elements in the Set and releases the memory they
+// used, so the Set is as if newly made.
+// See also [Set.Clear].
+func (me *Set[E]) Reset() {
+	me.set = make(map[E]struct{})
+	me.peak = 0
+}
+
+// Compact rebuilds the Set's underlying map so that it is no bigger than
+// its current number of elements requires. Go maps never shrink, so this
+// is worth doing after deleting most of a large Set's elements. Returns the
+// (estimated) number of element slots released.
+func (me *Set[E]) Compact() int {
+	released := max(0, me.peak-len(me.set))
+	set := make(map[E]struct{}, len(me.set))
+	for element := range me.set {
+		set[element] = struct{}{}
+	}
+	me.set = set
+	me.peak = len(set)
+	return released
+}
+
+// SetAutoShrink sets whether the Set should automatically [Set.Compact]
+// itself after a [Set.Delete] or [Set.Clear] leaves it with fewer than a
+// quarter of the elements it held at its largest. The default is false.
+func (me *Set[E]) SetAutoShrink(on bool) { me.autoShrink = on }
+
+// AutoShrink returns true if the Set automatically compacts itself;
+// otherwise returns false. See [Set.SetAutoShrink].
+func (me *Set[E]) AutoShrink() bool { return me.autoShrink }
+
+// sameMap returns true if a and b are the same map, e.g., that of a Set
+// and of a copy of it.
+func sameMap[E comparable](a, b map[E]struct{}) bool {
+	return reflect.ValueOf(a).UnsafePointer() ==
+		reflect.ValueOf(b).UnsafePointer()
+}
+
+func (me *Set[E]) maybeShrink() {
+	if me.autoShrink && len(me.set) < me.peak/4 {
+		me.Compact()
+	}
+}
 
 // Len returns the number of elements in the Set.
 func (me *Set[E]) Len() int { return len(me.set) }
@@ -58,20 +229,24 @@ func (me *Set[E]) Contains(element E) bool {
 
 // Difference returns a new Set that contains the elements which are in this
 // Set that are not in the other Set.
-func (me *Set[E]) Difference(other Set[E]) Set[E] {
-	diff := New[E]()
-	for element := range me.set {
-		if _, ok := other.set[element]; !ok {
-			diff.set[element] = struct{}{}
-		}
-	}
-	return diff
+// The result's capacity is estimated from a sample of the elements so that
+// it rarely needs to grow; if a hint is given it is used instead.
+func (me *Set[E]) Difference(other Set[E], hint ...int) Set[E] {
+	return differenceOf(me.set, other.set, hint)
 }
 
 // SymmetricDifference returns a new Set that contains the elements which
 // are in this Set or the other Set—but not in both Sets.
-func (me *Set[E]) SymmetricDifference(other Set[E]) Set[E] {
-	diff := New[E]()
+// The result's capacity is estimated from a sample of the elements so that
+// it rarely needs to grow; if a hint is given it is used instead.
+func (me *Set[E]) SymmetricDifference(other Set[E], hint ...int) Set[E] {
+	small, large := me.set, other.set
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+	common := estimate(small, large, true)
+	diff := Set[E]{set: make(map[E]struct{}, capacity(hint,
+		len(small)+len(large)-2*common, len(small)+len(large)))}
 	for element := range me.set {
 		if _, ok := other.set[element]; !ok {
 			diff.set[element] = struct{}{}
@@ -87,10 +262,65 @@ func (me *Set[E]) SymmetricDifference(other Set[E]) Set[E] {
 
 // Intersection returns a new Set that contains the elements this Set has in
 // common with the other Set.
-func (me *Set[E]) Intersection(other Set[E]) Set[E] {
-	intersection := New[E]()
-	for element := range me.set {
-		if _, ok := other.set[element]; ok {
+// The result's capacity is estimated from a sample of the elements so that
+// it rarely needs to grow; if a hint is given it is used instead.
+func (me *Set[E]) Intersection(other Set[E], hint ...int) Set[E] {
+	return intersectionOf(me.set, other.set, hint)
+}
+
+// estimateSample is the most elements estimate examines.
+const estimateSample = 64
+
+// estimate returns an estimate of how many of a's elements are (if in is
+// true) or aren't (if in is false) in b, based on a sample of a's elements
+// (which is exact if a is small). Map iteration starts at a random point,
+// so the sample differs from call to call.
+func estimate[E comparable](a, b map[E]struct{}, in bool) int {
+	seen, hits := 0, 0
+	for element := range a {
+		if _, ok := b[element]; ok == in {
+			hits++
+		}
+		if seen++; seen == estimateSample {
+			break
+		}
+	}
+	if seen == 0 {
+		return 0
+	}
+	return hits * len(a) / seen
+}
+
+// capacity returns hint[0] if given; otherwise it returns the estimate
+// plus some slack (so that a slight underestimate doesn't cause the map to
+// grow), but no more than the upper bound.
+func capacity(hint []int, estimate, upper int) int {
+	if len(hint) > 0 {
+		return max(0, hint[0])
+	}
+	return max(0, min(upper, estimate+estimate/8+8))
+}
+
+func differenceOf[E comparable](a, b map[E]struct{}, hint []int) Set[E] {
+	diff := Set[E]{set: make(map[E]struct{}, capacity(hint,
+		estimate(a, b, false), len(a)))}
+	for element := range a {
+		if _, ok := b[element]; !ok {
+			diff.set[element] = struct{}{}
+		}
+	}
+	return diff
+}
+
+// intersectionOf iterates the smaller map and probes the larger.
+func intersectionOf[E comparable](a, b map[E]struct{}, hint []int) Set[E] {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	intersection := Set[E]{set: make(map[E]struct{}, capacity(hint,
+		estimate(a, b, true), len(a)))}
+	for element := range a {
+		if _, ok := b[element]; ok {
 			intersection.set[element] = struct{}{}
 		}
 	}
@@ -115,9 +345,26 @@ func (me *Set[E]) Unite(other Set[E]) {
 	}
 }
 
+// Find returns an element of this Set for which pred returns true and
+// true; or the zero value and false if there is no such element. Which
+// element is found if more than one matches is unspecified.
+func (me *Set[E]) Find(pred func(E) bool) (E, bool) {
+	for element := range me.set {
+		if pred(element) {
+			return element, true
+		}
+	}
+	var zero E
+	return zero, false
+}
+
 // Clone returns a copy of this Set.
 func (me *Set[E]) Clone() Set[E] {
-	return Set[E]{maps.Clone(me.set)}
+	set := maps.Clone(me.set)
+	if set == nil { // e.g., cloning a zero Set
+		set = make(map[E]struct{})
+	}
+	return Set[E]{set: set, peak: me.peak, autoShrink: me.autoShrink}
 }
 
 // Equal returns true if this Set has the same elements as the other Set;
@@ -151,8 +398,8 @@ func (me *Set[E]) IsSubsetOf(other Set[E]) bool {
 // IsSupersetOf returns true if this Set is a superset of the other Set,
 // i.e., if every member of the other Set is in this Set; otherwise returns
 // false.
-func (me Set[E]) IsSupersetOf(other Set[E]) bool {
-	return other.IsSubsetOf(me)
+func (me *Set[E]) IsSupersetOf(other Set[E]) bool {
+	return other.IsSubsetOf(*me)
 }
 
 // All returns an iterator, e.g., for element := range aset.All() ...
@@ -166,6 +413,57 @@ func (me *Set[E]) All() iter.Seq[E] {
 	}
 }
 
+// DifferenceAll returns an iterator over the elements which are in this
+// Set that are not in the other Set, without making a new Set. The
+// elements are computed as they are iterated.
+// See also [Set.Difference].
+func (me *Set[E]) DifferenceAll(other Set[E]) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for element := range me.set {
+			if _, ok := other.set[element]; !ok && !yield(element) {
+				return
+			}
+		}
+	}
+}
+
+// IntersectionAll returns an iterator over the elements this Set has in
+// common with the other Set, without making a new Set. The elements are
+// computed as they are iterated.
+// See also [Set.Intersection].
+func (me *Set[E]) IntersectionAll(other Set[E]) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		small, large := me.set, other.set
+		if len(small) > len(large) {
+			small, large = large, small
+		}
+		for element := range small {
+			if _, ok := large[element]; ok && !yield(element) {
+				return
+			}
+		}
+	}
+}
+
+// UnionAll returns an iterator over the elements from this Set and from
+// the other Set (with no duplicates), without making a new Set. The
+// elements are computed as they are iterated.
+// See also [Set.Union].
+func (me *Set[E]) UnionAll(other Set[E]) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for element := range me.set {
+			if !yield(element) {
+				return
+			}
+		}
+		for element := range other.set {
+			if _, ok := me.set[element]; !ok && !yield(element) {
+				return
+			}
+		}
+	}
+}
+
 // AllX returns an iterator, e.g.,
 // for count, element := range aset.AllX(1) ...
 func (me *Set[E]) AllX(start ...int) iter.Seq2[int, E] {
@@ -185,7 +483,8 @@ func (me *Set[E]) AllX(start ...int) iter.Seq2[int, E] {
 
 // ToSlice returns this Set's elements as an unsorted slice.
 // For iteration either use this, or if you only need one value at a time,
-// use [All] or [AllX]. To sort, use slices.Sorted (if E is cmp.Orderable).
+// use [All] or [AllX]. To sort, use slices.Sorted (if E is cmp.Orderable)
+// or [Set.ToSliceFunc].
 func (me *Set[E]) ToSlice() []E {
 	slice := make([]E, 0, len(me.set))
 	for element := range me.set {
@@ -194,6 +493,24 @@ func (me *Set[E]) ToSlice() []E {
 	return slice
 }
 
+// ToSliceFunc returns this Set's elements as a slice sorted using the
+// given less function, which must return true if a should come before b.
+// This is useful for Sets whose elements aren't cmp.Orderable, e.g.,
+// structs.
+func (me *Set[E]) ToSliceFunc(less func(a, b E) bool) []E {
+	slice := me.ToSlice()
+	slices.SortFunc(slice, func(a, b E) int {
+		if less(a, b) {
+			return -1
+		}
+		if less(b, a) {
+			return 1
+		}
+		return 0
+	})
+	return slice
+}
+
 // String returns a human readable string representation of the Set.
 func (me *Set[E]) String() string {
 	format := "%s%v"
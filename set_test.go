@@ -106,6 +106,25 @@ func TestClear(t *testing.T) {
 	s := New(19, 21, 1, 2, 5, 4, 8, 9, 11, 13, 7)
 	s.Clear()
 	check(sortedStr(s), s.Len(), "{}", 0, t)
+	if s.peak != 11 {
+		t.Errorf("expected Clear to keep peak of 11, got %d", s.peak)
+	}
+	s.Add(1, 2, 3)
+	check(sortedStr(s), s.Len(), "{1 2 3}", 3, t)
+}
+
+func TestReset(t *testing.T) {
+	s := New(19, 21, 1, 2, 5, 4, 8, 9, 11, 13, 7)
+	old := s.set
+	s.Delete(19)
+	s.Reset()
+	check(sortedStr(s), s.Len(), "{}", 0, t)
+	if s.peak != 0 {
+		t.Errorf("expected Reset to zero peak, got %d", s.peak)
+	}
+	if len(old) != 10 {
+		t.Errorf("expected Reset to leave old map alone, got %d", len(old))
+	}
 	s.Add(1, 2, 3)
 	check(sortedStr(s), s.Len(), "{1 2 3}", 3, t)
 }
@@ -330,3 +349,232 @@ func TestEg(t *testing.T) {
 	slices.Sort(v)
 	check(fmt.Sprintf("%v", v), len(v), "[1 3 5 6]", d.Len(), t)
 }
+
+func TestAddSeqWithStats(t *testing.T) {
+	s := New(1, 2, 3)
+	stats := s.AddSeqWithStats(slices.Values([]int{2, 4, 5, 4, 6, 1}))
+	check(sortedStr(s), s.Len(), "{1 2 3 4 5 6}", 6, t)
+	if stats.Seen != 6 || stats.Duplicates != 3 || stats.Added != 3 {
+		t.Errorf("expected {6 3 3}, got %v", stats)
+	}
+	if r := stats.DedupRatio(); r != 0.5 {
+		t.Errorf("expected 0.5, got %g", r)
+	}
+	var empty LoadStats
+	if r := empty.DedupRatio(); r != 0.0 {
+		t.Errorf("expected 0.0, got %g", r)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	s := New[int]()
+	for i := range 100 {
+		s.Add(i)
+	}
+	for i := range 90 {
+		s.Delete(i)
+	}
+	if n := s.Compact(); n != 90 {
+		t.Errorf("expected 90 released, got %d", n)
+	}
+	check(sortedStr(s), s.Len(), "{90 91 92 93 94 95 96 97 98 99}", 10, t)
+	if n := s.Compact(); n != 0 {
+		t.Errorf("expected 0 released, got %d", n)
+	}
+}
+
+func TestAutoShrink(t *testing.T) {
+	s := New[int]()
+	if s.AutoShrink() {
+		t.Error("expected auto-shrink to be off by default")
+	}
+	s.SetAutoShrink(true)
+	for i := range 100 {
+		s.Add(i)
+	}
+	s.Delete(0, 1, 2, 3, 4)
+	if s.peak != 100 {
+		t.Errorf("expected peak of 100, got %d", s.peak)
+	}
+	for i := 5; i < 80; i++ {
+		s.Delete(i)
+	}
+	if s.peak != 24 {
+		t.Errorf("expected auto-shrink to peak 24, got %d", s.peak)
+	}
+	if !s.Contains(80) || s.Len() != 20 {
+		t.Errorf("expected 20 elements from 80, got %v", s)
+	}
+	u := s.Clone()
+	if !u.AutoShrink() {
+		t.Error("expected clone to keep auto-shrink")
+	}
+	s.Clear()
+	if s.peak != 0 || !s.IsEmpty() {
+		t.Errorf("expected empty compacted set, got %d %v", s.peak, s)
+	}
+}
+
+func TestToSliceFunc(t *testing.T) {
+	type point struct{ x, y int }
+	s := New(point{3, 1}, point{1, 2}, point{1, 1}, point{2, 9})
+	u := s.ToSliceFunc(func(a, b point) bool {
+		return a.x < b.x || (a.x == b.x && a.y < b.y)
+	})
+	check(fmt.Sprintf("%v", u), len(u), "[{1 1} {1 2} {2 9} {3 1}]", s.Len(),
+		t)
+	w := New(5, 3, 8, 1)
+	v := w.ToSliceFunc(func(a, b int) bool { return a > b })
+	check(fmt.Sprintf("%v", v), len(v), "[8 5 3 1]", 4, t)
+}
+
+func TestFind(t *testing.T) {
+	s := New(1, 4, 9, 16)
+	if x, ok := s.Find(func(x int) bool { return x > 10 }); !ok || x != 16 {
+		t.Errorf("expected 16, got %d %t", x, ok)
+	}
+	if x, ok := s.Find(func(x int) bool { return x < 0 }); ok || x != 0 {
+		t.Errorf("expected zero and false, got %d %t", x, ok)
+	}
+	z := NewSorted(1, 4, 9, 16)
+	if x, ok := z.Find(func(x int) bool { return x%2 == 0 }); !ok ||
+		x != 4 {
+		t.Errorf("expected 4, got %d %t", x, ok)
+	}
+	f := NewFrozen("a", "bb", "ccc")
+	if x, ok := Find[string](&f, func(x string) bool {
+		return len(x) == 2
+	}); !ok || x != "bb" {
+		t.Errorf("expected bb, got %q %t", x, ok)
+	}
+}
+
+func TestExtractFunc(t *testing.T) {
+	s := New(1, 2, 3, 4, 5, 6)
+	even := s.ExtractFunc(func(x int) bool { return x%2 == 0 })
+	check(sortedStr(even), even.Len(), "{2 4 6}", 3, t)
+	check(sortedStr(s), s.Len(), "{1 3 5}", 3, t)
+	none := s.ExtractFunc(func(x int) bool { return x > 10 })
+	check(sortedStr(none), none.Len(), "{}", 0, t)
+	check(sortedStr(s), s.Len(), "{1 3 5}", 3, t)
+}
+
+func TestReplaceFunc(t *testing.T) {
+	s := New("Alpha", "ALPHA", "beta", "Gamma")
+	if n := s.ReplaceFunc(strings.ToLower); n != 1 {
+		t.Errorf("expected 1 collapsed, got %d", n)
+	}
+	check(sortedStr(s), s.Len(), `{"alpha" "beta" "gamma"}`, 3, t)
+	if n := s.ReplaceFunc(strings.ToLower); n != 0 {
+		t.Errorf("expected 0 collapsed, got %d", n)
+	}
+	alias := s
+	s.ReplaceFunc(strings.ToUpper)
+	check(sortedStr(alias), alias.Len(), `{"ALPHA" "BETA" "GAMMA"}`, 3, t)
+}
+
+func TestMoveTo(t *testing.T) {
+	pending := New(1, 2, 3, 4, 5)
+	inFlight := New(4)
+	n := pending.MoveTo(&inFlight, func(x int) bool { return x > 2 })
+	if n != 3 {
+		t.Errorf("expected 3 moved, got %d", n)
+	}
+	check(sortedStr(pending), pending.Len(), "{1 2}", 2, t)
+	check(sortedStr(inFlight), inFlight.Len(), "{3 4 5}", 3, t)
+	if n := pending.MoveTo(&pending, func(int) bool { return true }); n != 0 {
+		t.Errorf("expected self-move to do nothing, got %d", n)
+	}
+	alias := pending
+	if n := pending.MoveTo(&alias, func(int) bool { return true }); n != 0 {
+		t.Errorf("expected aliased move to do nothing, got %d", n)
+	}
+	check(sortedStr(pending), pending.Len(), "{1 2}", 2, t)
+}
+
+func TestAlgebraCapacityHint(t *testing.T) {
+	a, b := New[int](), New[int]()
+	for i := range 1000 {
+		a.Add(i)
+		b.Add(i + 900)
+	}
+	for _, hint := range [][]int{nil, {0}, {100}, {-1}} {
+		if x := a.Intersection(b, hint...); x.Len() != 100 ||
+			!x.Contains(950) {
+			t.Errorf("hint %v: unexpected intersection of %d", hint,
+				x.Len())
+		}
+		if d := a.Difference(b, hint...); d.Len() != 900 ||
+			d.Contains(950) {
+			t.Errorf("hint %v: unexpected difference of %d", hint,
+				d.Len())
+		}
+		if d := a.SymmetricDifference(b, hint...); d.Len() != 1800 {
+			t.Errorf("hint %v: unexpected symmetric difference of %d",
+				hint, d.Len())
+		}
+	}
+	if n := capacity(nil, 100, 50); n != 50 {
+		t.Errorf("expected capacity capped at 50, got %d", n)
+	}
+	if n := capacity(nil, 0, 1000); n != 8 {
+		t.Errorf("expected minimum slack of 8, got %d", n)
+	}
+}
+
+func TestAlgebraAll(t *testing.T) {
+	a := New(1, 2, 3, 4)
+	b := New(3, 4, 5)
+	for _, c := range []struct {
+		name string
+		seq  func(yield func(int) bool)
+		exp  []int
+	}{
+		{"DifferenceAll", a.DifferenceAll(b), []int{1, 2}},
+		{"IntersectionAll", a.IntersectionAll(b), []int{3, 4}},
+		{"UnionAll", a.UnionAll(b), []int{1, 2, 3, 4, 5}},
+	} {
+		if act := slices.Sorted(c.seq); !slices.Equal(act, c.exp) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.exp, act)
+		}
+	}
+	n := 0
+	for range a.UnionAll(b) {
+		if n++; n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("expected early stop, got %d", n)
+	}
+}
+
+func TestResultsHaveFreshStorage(t *testing.T) {
+	elements := []int{1, 2, 3}
+	a := New(elements...)
+	b := New(3, 4)
+	for name, result := range map[string]Set[int]{
+		"Clone":               a.Clone(),
+		"Union":               a.Union(b),
+		"Intersection":        a.Intersection(b),
+		"Difference":          a.Difference(b),
+		"SymmetricDifference": a.SymmetricDifference(b),
+		"NewFrom":             NewFrom[int](&a),
+	} {
+		result.Add(99)
+		result.Delete(1, 3)
+		if a.Len() != 3 || b.Len() != 2 || a.Contains(99) ||
+			b.Contains(99) {
+			t.Errorf("%s: result shares storage with an operand", name)
+		}
+	}
+	elements[0] = 100
+	if !a.Contains(1) {
+		t.Error("New shares storage with its argument")
+	}
+	alias := a
+	alias.Add(7)
+	if !a.Contains(7) {
+		t.Error("expected a copied Set value to share storage")
+	}
+}
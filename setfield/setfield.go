@@ -0,0 +1,120 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+// Package setfield provides reflection-based helpers for building and
+// comparing sets keyed by a struct field, for quick data-wrangling where
+// writing a key function for every struct type is overhead. A field is
+// named either by its Go name or by a `set:"name"` struct tag. For
+// example:
+//
+//	type User struct {
+//		ID    int `set:"id"`
+//		Email string
+//	}
+//	ids, err := setfield.ByField(users, "id")
+//	gone, err := setfield.DiffByField(oldUsers, users, "Email")
+//
+// Since these use reflection they are much slower than an explicit key
+// function and report type mistakes at run time.
+package setfield
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mark-summerfield/set"
+)
+
+// ByField returns a Set of the given field's values across the items,
+// which must be structs or non-nil pointers to structs. Returns an error
+// if the field doesn't exist or its type isn't comparable (even if there
+// are no items), or if a value of an interface-typed field isn't.
+func ByField[T any](items []T, field string) (set.Set[any], error) {
+	keys := set.New[any]()
+	index, err := fieldIndex(reflect.TypeFor[T](), field)
+	if err != nil {
+		return keys, err
+	}
+	for i := range items {
+		key, err := keyOf(items[i], index, field)
+		if err != nil {
+			return keys, err
+		}
+		keys.Add(key)
+	}
+	return keys, nil
+}
+
+// DiffByField returns the items in a whose field value isn't the field
+// value of any item in b, in their original order. Returns an error if
+// the field doesn't exist or its type isn't comparable, or if a value of
+// an interface-typed field isn't.
+func DiffByField[T any](a, b []T, field string) ([]T, error) {
+	index, err := fieldIndex(reflect.TypeFor[T](), field)
+	if err != nil {
+		return nil, err
+	}
+	other := set.New[any]()
+	for i := range b {
+		key, err := keyOf(b[i], index, field)
+		if err != nil {
+			return nil, err
+		}
+		other.Add(key)
+	}
+	var diff []T
+	for i := range a {
+		key, err := keyOf(a[i], index, field)
+		if err != nil {
+			return nil, err
+		}
+		if !other.Contains(key) {
+			diff = append(diff, a[i])
+		}
+	}
+	return diff, nil
+}
+
+// fieldIndex returns the index of the struct field named (or tagged)
+// field in t or in the struct t points to.
+func fieldIndex(t reflect.Type, field string) ([]int, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot get field %q of non-struct type %v",
+			field, t)
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.IsExported() && (f.Tag.Get("set") == field ||
+			(f.Name == field && f.Tag.Get("set") == "")) {
+			if !f.Type.Comparable() {
+				return nil, fmt.Errorf(
+					"cannot use field %q of uncomparable type %v", field,
+					f.Type)
+			}
+			return f.Index, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot find field %q in type %v", field, t)
+}
+
+// keyOf returns item's field value at index. The field's type is known to
+// be comparable, but if it is an interface type the value (e.g., a slice)
+// may not be.
+func keyOf(item any, index []int, field string) (any, error) {
+	value := reflect.ValueOf(item)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil, fmt.Errorf("cannot get field of nil %T", item)
+		}
+		value = value.Elem()
+	}
+	value = value.FieldByIndex(index)
+	if !value.Comparable() {
+		return nil, fmt.Errorf(
+			"cannot use field %q's uncomparable value of type %v", field,
+			value.Elem().Type())
+	}
+	return value.Interface(), nil
+}
@@ -0,0 +1,70 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package setfield
+
+import "testing"
+
+type user struct {
+	ID    int `set:"id"`
+	Email string
+	Tags  []string
+}
+
+func TestByField(t *testing.T) {
+	users := []user{{1, "a@x", nil}, {2, "b@x", nil}, {1, "c@x", nil}}
+	ids, err := ByField(users, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids.Len() != 2 || !ids.Contains(1) || !ids.Contains(2) {
+		t.Errorf("expected {1 2}, got %v", ids.String())
+	}
+	if _, err := ByField(users, "ID"); err == nil {
+		t.Error("expected tagged field to be found only by its tag")
+	}
+	if _, err := ByField(users, "Tags"); err == nil {
+		t.Error("expected error for uncomparable field")
+	}
+	emails, err := ByField([]*user{&users[0]}, "Email")
+	if err != nil || !emails.Contains("a@x") {
+		t.Errorf("expected {a@x}, got %v %v", emails.String(), err)
+	}
+	if _, err := ByField([]*user{nil}, "Email"); err == nil {
+		t.Error("expected error for nil pointer")
+	}
+	if _, err := ByField([]int{1}, "x"); err == nil {
+		t.Error("expected error for non-struct")
+	}
+	if _, err := ByField([]user{}, "Name"); err == nil {
+		t.Error("expected error for missing field with no items")
+	}
+}
+
+func TestByFieldInterface(t *testing.T) {
+	type item struct{ Key any }
+	keys, err := ByField([]item{{1}, {"a"}, {nil}, {1}}, "Key")
+	if err != nil || keys.Len() != 3 {
+		t.Errorf("expected {1 a <nil>}, got %v %v", keys.String(), err)
+	}
+	if _, err := ByField([]item{{1}, {[]int{2}}}, "Key"); err == nil {
+		t.Error("expected error for uncomparable value")
+	}
+	_, err = DiffByField([]item{{1}}, []item{{map[int]int{}}}, "Key")
+	if err == nil {
+		t.Error("expected error for uncomparable value")
+	}
+}
+
+func TestDiffByField(t *testing.T) {
+	old := []user{{1, "a@x", nil}, {2, "b@x", nil}, {3, "c@x", nil}}
+	now := []user{{2, "b@x", nil}, {4, "d@x", nil}}
+	gone, err := DiffByField(old, now, "Email")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gone) != 2 || gone[0].ID != 1 || gone[1].ID != 3 {
+		t.Errorf("expected users 1 and 3, got %v", gone)
+	}
+	if _, err := DiffByField(old, now, "Name"); err == nil {
+		t.Error("expected error for missing field")
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+// Package sethttp provides an [http.Handler] for inspecting the contents
+// of a live service's sets while debugging, rather like expvar but for set
+// contents. For example:
+//
+//	debug := sethttp.New()
+//	sethttp.Register(debug, "sessions", &sessions) // e.g., a *set.SyncSet
+//	http.Handle("/debug/sets", debug)
+//
+// GET /debug/sets lists the registered sets and their lengths;
+// /debug/sets?name=sessions shows that set's elements sorted (by their
+// string form) and paginated. The query parameters are:
+//
+//   - name: the set to show
+//   - q: only show elements whose string form contains this
+//   - page: the page to show (from 1, the default; past the end shows
+//     the last page)
+//   - size: the elements per page (default 100, at most [MaxPageSize])
+//   - format: "json" for JSON rather than HTML
+//
+// The handler reads the sets while the service may be changing them, so
+// only register sets that are safe for concurrent use (e.g., [set.SyncSet]
+// or [set.ReadMostlySet]) or that aren't changed once built.
+package sethttp
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"iter"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mark-summerfield/set"
+)
+
+const (
+	// MaxPageSize is the most elements shown per page.
+	MaxPageSize = 1000
+	// DefaultMaxScan is the default for [Handler.MaxScan].
+	DefaultMaxScan = 100_000
+	defaultSize    = 100
+)
+
+// Handler is an http.Handler that shows the registered sets. Make one with
+// [New] and add sets to it with [Register].
+type Handler struct {
+	// MaxScan is the size guard: the most elements read from a set per
+	// request. A bigger set is shown (and searched) only in part, in
+	// which case the page says so. If it is 0 DefaultMaxScan is used.
+	MaxScan int
+	mutex   sync.RWMutex
+	sets    map[string]source
+}
+
+type source struct {
+	len func() int
+	all func() iter.Seq[string]
+}
+
+// New returns a new Handler with no registered sets.
+func New() *Handler {
+	return &Handler{MaxScan: DefaultMaxScan, sets: map[string]source{}}
+}
+
+// Register adds the given set (which may be of any of the set package's
+// types) to the Handler under the given name, replacing any set already
+// registered with that name. Elements are shown using fmt's %v.
+func Register[E comparable](h *Handler, name string, s set.Interface[E]) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.sets[name] = source{len: s.Len, all: func() iter.Seq[string] {
+		return func(yield func(string) bool) {
+			for element := range s.All() {
+				if !yield(fmt.Sprint(element)) {
+					return
+				}
+			}
+		}
+	}}
+}
+
+// Unregister removes the set with the given name (if any).
+func (me *Handler) Unregister(name string) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	delete(me.sets, name)
+}
+
+// Summary is a registered set's name and length as reported by the index.
+type Summary struct {
+	Name string `json:"name"`
+	Len  int    `json:"len"`
+}
+
+// Page is one page of a set's elements as reported by the Handler.
+type Page struct {
+	Name      string   `json:"name"`
+	Len       int      `json:"len"`       // the set's length
+	Query     string   `json:"query"`     // the q parameter
+	Matched   int      `json:"matched"`   // elements matching the query
+	Page      int      `json:"page"`      // from 1 to Pages
+	Pages     int      `json:"pages"`     // at least 1
+	Size      int      `json:"size"`      // elements per page
+	Truncated bool     `json:"truncated"` // true if MaxScan was reached
+	Elements  []string `json:"elements"`
+}
+
+// ServeHTTP implements http.Handler.
+func (me *Handler) ServeHTTP(writer http.ResponseWriter,
+	request *http.Request) {
+	if request.Method != http.MethodGet &&
+		request.Method != http.MethodHead {
+		writer.Header().Set("Allow", "GET, HEAD")
+		http.Error(writer, "method not allowed",
+			http.StatusMethodNotAllowed)
+		return
+	}
+	query := request.URL.Query()
+	asJSON := query.Get("format") == "json"
+	name := query.Get("name")
+	if name == "" {
+		me.respond(writer, asJSON, indexTemplate, me.index())
+		return
+	}
+	me.mutex.RLock()
+	src, ok := me.sets[name]
+	me.mutex.RUnlock()
+	if !ok {
+		http.Error(writer, "no set named "+strconv.Quote(name),
+			http.StatusNotFound)
+		return
+	}
+	page, err := me.page(name, src, query.Get("q"), query.Get("page"),
+		query.Get("size"))
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	me.respond(writer, asJSON, pageTemplate, page)
+}
+
+func (me *Handler) index() []Summary {
+	me.mutex.RLock()
+	defer me.mutex.RUnlock()
+	summaries := make([]Summary, 0, len(me.sets))
+	for name, src := range me.sets {
+		summaries = append(summaries, Summary{Name: name, Len: src.len()})
+	}
+	slices.SortFunc(summaries, func(a, b Summary) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return summaries
+}
+
+func (me *Handler) page(name string, src source, q, pageText,
+	sizeText string) (Page, error) {
+	page := Page{Name: name, Len: src.len(), Query: q, Page: 1,
+		Size: defaultSize}
+	var err error
+	if pageText != "" {
+		// Any page past the end shows the last page (see below), so
+		// numbers too big for an int are fine.
+		n, err := strconv.ParseUint(pageText, 10, 64)
+		if (err != nil && !errors.Is(err, strconv.ErrRange)) || n < 1 {
+			return page, fmt.Errorf("invalid page %q", pageText)
+		}
+		page.Page = int(min(n, math.MaxInt))
+	}
+	if sizeText != "" {
+		if page.Size, err = strconv.Atoi(sizeText); err != nil ||
+			page.Size < 1 {
+			return page, fmt.Errorf("invalid size %q", sizeText)
+		}
+		page.Size = min(page.Size, MaxPageSize)
+	}
+	var matched []string
+	scanned, limit := 0, cmp.Or(max(0, me.MaxScan), DefaultMaxScan)
+	for element := range src.all() {
+		if scanned == limit {
+			page.Truncated = true
+			break
+		}
+		scanned++
+		if strings.Contains(element, q) {
+			matched = append(matched, element)
+		}
+	}
+	slices.Sort(matched)
+	page.Matched = len(matched)
+	page.Pages = max(1, (len(matched)+page.Size-1)/page.Size)
+	page.Page = min(page.Page, page.Pages) // so start can't overflow
+	start := min(len(matched), (page.Page-1)*page.Size)
+	page.Elements = matched[start:min(len(matched), start+page.Size)]
+	return page, nil
+}
+
+func (me *Handler) respond(writer http.ResponseWriter, asJSON bool,
+	tmpl *template.Template, data any) {
+	if asJSON {
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(data)
+		return
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = tmpl.Execute(writer, data)
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(
+	`<!DOCTYPE html>
+<html><head><title>Sets</title></head><body>
+<h1>Sets</h1>
+<table>
+<tr><th>Name</th><th>Length</th></tr>
+{{range .}}<tr><td><a href="?name={{.Name}}">{{.Name}}</a></td>` +
+		`<td>{{.Len}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+var pageTemplate = template.Must(template.New("page").Funcs(
+	template.FuncMap{
+		"add":   func(a, b int) int { return a + b },
+		"first": func(p Page) int { return (p.Page-1)*p.Size + 1 },
+	}).Parse(
+	`<!DOCTYPE html>
+<html><head><title>{{.Name}}</title></head><body>
+<h1>{{.Name}}</h1>
+<p><a href="?">All sets</a></p>
+<form><input type="hidden" name="name" value="{{.Name}}">
+<input name="q" value="{{.Query}}" placeholder="Search">
+<input type="hidden" name="size" value="{{.Size}}"></form>
+<p>{{.Len}} elements; {{len .Elements}} of {{.Matched}}{{if .Query}}
+matching &ldquo;{{.Query}}&rdquo;{{end}} shown; page {{.Page}} of
+{{.Pages}}.
+{{if .Truncated}}<strong>Only part of the set was read (size guard
+reached).</strong>{{end}}</p>
+<ol start="{{first .}}">
+{{range .Elements}}<li>{{.}}</li>
+{{end}}</ol>
+<p>{{if gt .Page 1}}<a href="?name={{.Name}}&amp;q={{.Query}}` +
+		`&amp;size={{.Size}}&amp;page={{add .Page -1}}">Previous</a>
+{{end}}{{if lt .Page .Pages}}<a href="?name={{.Name}}&amp;` +
+		`q={{.Query}}&amp;size={{.Size}}&amp;page={{add .Page 1}}">` +
+		`Next</a>{{end}}</p>
+</body></html>
+`))
@@ -0,0 +1,130 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package sethttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mark-summerfield/set"
+)
+
+func get(t *testing.T, h http.Handler,
+	target string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func getPage(t *testing.T, h http.Handler, target string) Page {
+	t.Helper()
+	recorder := get(t, h, target+"&format=json")
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("%s: expected 200, got %d", target, recorder.Code)
+	}
+	var page Page
+	if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func newHandler() *Handler {
+	h := New()
+	ids := set.NewSync[int]()
+	for i := range 250 {
+		ids.Add(i)
+	}
+	colors := set.New("red", "green", "blue", "<b>")
+	Register[int](h, "ids", ids)
+	Register[string](h, "colors", &colors)
+	return h
+}
+
+func TestIndex(t *testing.T) {
+	h := newHandler()
+	recorder := get(t, h, "/?format=json")
+	var summaries []Summary
+	if err := json.Unmarshal(recorder.Body.Bytes(), &summaries); err != nil {
+		t.Fatal(err)
+	}
+	exp := []Summary{{"colors", 4}, {"ids", 250}}
+	if !slices.Equal(summaries, exp) {
+		t.Errorf("expected %v, got %v", exp, summaries)
+	}
+	body := get(t, h, "/").Body.String()
+	if !strings.Contains(body, `href="?name=ids"`) {
+		t.Errorf("expected a link to ids in %s", body)
+	}
+	h.Unregister("ids")
+	if recorder := get(t, h, "/?name=ids"); recorder.Code !=
+		http.StatusNotFound {
+		t.Errorf("expected 404, got %d", recorder.Code)
+	}
+}
+
+func TestPage(t *testing.T) {
+	h := newHandler()
+	page := getPage(t, h, "/?name=ids&size=100&page=3")
+	if page.Len != 250 || page.Matched != 250 || page.Pages != 3 ||
+		len(page.Elements) != 50 || page.Truncated {
+		t.Errorf("unexpected page %+v", page)
+	}
+	// Sorted by string form.
+	if page.Elements[0] != "54" || page.Elements[49] != "99" {
+		t.Errorf("unexpected elements %v", page.Elements)
+	}
+	page = getPage(t, h, "/?name=ids&q=24")
+	if page.Matched != 13 || !slices.Equal(page.Elements[:3],
+		[]string{"124", "224", "24"}) {
+		t.Errorf("unexpected search result %+v", page)
+	}
+	page = getPage(t, h, "/?name=ids&size=5000")
+	if page.Size != MaxPageSize {
+		t.Errorf("expected size capped at %d, got %d", MaxPageSize,
+			page.Size)
+	}
+	h.MaxScan = 10
+	page = getPage(t, h, "/?name=ids")
+	if !page.Truncated || page.Matched != 10 {
+		t.Errorf("expected the size guard to apply, got %+v", page)
+	}
+	h.MaxScan = 0
+	page = getPage(t, h, "/?name=ids&page=9223372036854775807&size=1000")
+	if page.Page != 1 || len(page.Elements) != 250 {
+		t.Errorf("expected the page clamped to 1, got %+v", page)
+	}
+	for _, bad := range []string{"page=0", "page=x", "size=-1"} {
+		if recorder := get(t, h, "/?name=ids&"+bad); recorder.Code !=
+			http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", bad, recorder.Code)
+		}
+	}
+}
+
+func TestPageHTML(t *testing.T) {
+	h := newHandler()
+	body := get(t, h, "/?name=colors&size=2").Body.String()
+	for _, want := range []string{"&lt;b&gt;", "<li>blue</li>",
+		"2 of 4 shown", "page 1 of\n2", "page=2"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in %s", want, body)
+		}
+	}
+	if strings.Contains(body, "<b>") {
+		t.Error("expected elements to be escaped")
+	}
+	body = get(t, h, "/?name=ids&page=2").Body.String()
+	if !strings.Contains(body, `<ol start="101">`) {
+		t.Errorf("expected the list to start at 101 in %s", body)
+	}
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", recorder.Code)
+	}
+}
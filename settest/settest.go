@@ -0,0 +1,184 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+// Package settest provides fuzzing helpers for the set package's parsing
+// layers (JSON, [set.Codec], and [set.FrozenStringSet] decoding), so that
+// users can fuzz their own element types through them. For example:
+//
+//	func FuzzTagSetJSON(f *testing.F) {
+//		settest.SeedJSON(f, Tag("a"), Tag("b"))
+//		settest.FuzzJSON[Tag](f)
+//	}
+package settest
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/mark-summerfield/set"
+)
+
+// SeedJSON adds a corpus of JSON inputs built from the given sample
+// elements to f: an empty array, null, each sample alone, all of them,
+// all of them with duplicates and varied whitespace, and some malformed
+// variants.
+func SeedJSON[E comparable](f *testing.F, samples ...E) {
+	f.Helper()
+	for _, seed := range []string{"[]", "null", "[", "{}", `[1,`, `"x"`} {
+		f.Add([]byte(seed))
+	}
+	for _, sample := range samples {
+		raw, err := json.Marshal([]E{sample})
+		if err != nil {
+			f.Fatalf("cannot marshal sample %v: %v", sample, err)
+		}
+		f.Add(raw)
+	}
+	raw, _ := json.Marshal(samples)
+	f.Add(raw)
+	raw, _ = json.MarshalIndent(slices.Concat(samples, samples), " ", "\t")
+	f.Add(raw)
+	if len(raw) > 1 {
+		f.Add(raw[:len(raw)-1]) // unterminated
+	}
+}
+
+// FuzzJSON fuzzes unmarshaling JSON into a set.Set[E]. Inputs that don't
+// unmarshal are fine; those that do must marshal and unmarshal back to an
+// equal Set.
+func FuzzJSON[E comparable](f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var s set.Set[E]
+		if err := json.Unmarshal(data, &s); err != nil {
+			return
+		}
+		raw, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("cannot marshal %v: %v", s.String(), err)
+		}
+		var u set.Set[E]
+		if err := json.Unmarshal(raw, &u); err != nil {
+			t.Fatalf("cannot unmarshal %s: %v", raw, err)
+		}
+		if !s.Equal(u) {
+			t.Fatalf("round trip changed %v to %v", s.String(), u.String())
+		}
+	})
+}
+
+// SeedCodec adds a corpus of inputs encoded with the given codec from the
+// given sample elements to f: an empty set, each sample alone, all of
+// them, and some truncated and empty inputs.
+func SeedCodec[E comparable](f *testing.F, codec set.Codec[E],
+	samples ...E) {
+	f.Helper()
+	f.Add([]byte{})
+	add := func(elements ...E) []byte {
+		s := set.New(elements...)
+		var buf bytes.Buffer
+		if err := s.EncodeTo(&buf, codec); err != nil {
+			f.Fatalf("cannot encode samples %v: %v", elements, err)
+		}
+		f.Add(buf.Bytes())
+		return buf.Bytes()
+	}
+	add()
+	for _, sample := range samples {
+		add(sample)
+	}
+	if raw := add(samples...); len(raw) > 1 {
+		f.Add(raw[:len(raw)-1])
+	}
+}
+
+// FuzzCodec fuzzes decoding a set.Set[E] with the given codec using
+// [set.DecodeFrom]. Inputs that don't decode are fine; those that do must
+// encode and decode back to an equal Set.
+func FuzzCodec[E comparable](f *testing.F, codec set.Codec[E]) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		s, err := set.DecodeFrom(bytes.NewReader(data), codec)
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		if err := s.EncodeTo(&buf, codec); err != nil {
+			t.Fatalf("cannot encode %v: %v", s.String(), err)
+		}
+		u, err := set.DecodeFrom(&buf, codec)
+		if err != nil {
+			t.Fatalf("cannot decode re-encoded %v: %v", s.String(), err)
+		}
+		if !s.Equal(u) {
+			t.Fatalf("round trip changed %v to %v", s.String(), u.String())
+		}
+	})
+}
+
+// SeedFrozenStringSet adds a corpus of [set.FrozenStringSet] binary
+// encodings built from the given sample strings to f: an empty set, each
+// sample alone, all of them, and some truncated and corrupted variants.
+func SeedFrozenStringSet(f *testing.F, samples ...string) {
+	f.Helper()
+	add := func(elements ...string) []byte {
+		s := set.NewFrozenStringSet(elements...)
+		raw, err := s.MarshalBinary()
+		if err != nil {
+			f.Fatalf("cannot marshal samples %v: %v", elements, err)
+		}
+		f.Add(raw)
+		return raw
+	}
+	add()
+	for _, sample := range samples {
+		add(sample)
+	}
+	if raw := add(samples...); len(raw) > 1 {
+		f.Add(raw[:len(raw)-1])
+		corrupt := slices.Clone(raw)
+		corrupt[len(corrupt)-1] ^= 0xFF
+		f.Add(corrupt)
+	}
+}
+
+// FuzzFrozenStringSet fuzzes loading a [set.FrozenStringSet]. Sets loaded
+// unchecked (see [set.LoadFrozenStringSet]) must be queryable without
+// panicking. Inputs that don't unmarshal are fine; those that do must be
+// consistent: every element they iterate must be contained, and they must
+// marshal and unmarshal back to an equal set.
+func FuzzFrozenStringSet(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if s, err := set.LoadFrozenStringSet(data); err == nil {
+			for element := range s.All() {
+				s.Contains(element)
+			}
+		}
+		var s set.FrozenStringSet
+		if err := s.UnmarshalBinary(data); err != nil {
+			return
+		}
+		elements := s.ToSlice()
+		if len(elements) != s.Len() {
+			t.Fatalf("expected %d elements, got %d", s.Len(),
+				len(elements))
+		}
+		for _, element := range elements {
+			if !s.Contains(element) {
+				t.Fatalf("%q iterated but not contained", element)
+			}
+		}
+		raw, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("cannot marshal %v: %v", s.String(), err)
+		}
+		var u set.FrozenStringSet
+		if err := u.UnmarshalBinary(raw); err != nil {
+			t.Fatalf("cannot unmarshal re-marshaled %v: %v", s.String(),
+				err)
+		}
+		if !slices.Equal(elements, u.ToSlice()) {
+			t.Fatalf("round trip changed %v to %v", s.String(),
+				u.String())
+		}
+	})
+}
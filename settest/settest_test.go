@@ -0,0 +1,33 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package settest
+
+import (
+	"testing"
+
+	"github.com/mark-summerfield/set"
+)
+
+func FuzzJSONInt(f *testing.F) {
+	SeedJSON[int64](f, 0, -1, 1<<40)
+	FuzzJSON[int64](f)
+}
+
+func FuzzJSONString(f *testing.F) {
+	SeedJSON(f, "", "a", "é\t\"")
+	FuzzJSON[string](f)
+}
+
+func FuzzCodecInt(f *testing.F) {
+	SeedCodec(f, set.IntCodec[int64](), 0, -1, 1<<40)
+	FuzzCodec(f, set.IntCodec[int64]())
+}
+
+func FuzzCodecString(f *testing.F) {
+	SeedCodec(f, set.StringCodec[string](), "", "a", "bc")
+	FuzzCodec(f, set.StringCodec[string]())
+}
+
+func FuzzFrozenStringSetBinary(f *testing.F) {
+	SeedFrozenStringSet(f, "", "a", "ab", "b")
+	FuzzFrozenStringSet(f)
+}
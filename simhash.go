@@ -0,0 +1,50 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// SimHash returns a 64-bit SimHash fingerprint of the given weighted
+// elements (e.g., maps.All of a map[E]float64 of term weights), so that
+// the similarity of weighted sets can be estimated (see
+// [SimHashSimilarity]) taking account of weights, not just presence as
+// with [MinHash]. Elements with weights <= 0 are ignored. Elements are
+// hashed with [StableHash], so fingerprints agree between processes and
+// machines; an error is returned if an element isn't stable hashable.
+func SimHash[E comparable](weights iter.Seq2[E, float64]) (uint64, error) {
+	var totals [64]float64
+	for element, weight := range weights {
+		if weight <= 0 {
+			continue
+		}
+		h, err := StableHash(element)
+		if err != nil {
+			return 0, err
+		}
+		h = mix64(h) // FNV's high bits are weak for short inputs
+		for i := range totals {
+			if h&(1<<i) != 0 {
+				totals[i] += weight
+			} else {
+				totals[i] -= weight
+			}
+		}
+	}
+	var fingerprint uint64
+	for i, total := range totals {
+		if total > 0 {
+			fingerprint |= 1 << i
+		}
+	}
+	return fingerprint, nil
+}
+
+// SimHashSimilarity returns the similarity of two [SimHash] fingerprints
+// in the range [0, 1], i.e., the proportion of bits they agree on. Values
+// near 1 suggest near-duplicates; unrelated inputs give about 0.5.
+func SimHashSimilarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
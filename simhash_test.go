@@ -0,0 +1,49 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"maps"
+	"testing"
+)
+
+func TestSimHash(t *testing.T) {
+	doc := map[string]float64{}
+	for i := range 200 {
+		doc[fmt.Sprintf("term%d", i)] = float64(1 + i%5)
+	}
+	near := maps.Clone(doc)
+	near["term0"] = 2
+	near["extra"] = 1
+	other := map[string]float64{}
+	for i := range 200 {
+		other[fmt.Sprintf("word%d", i)] = 1
+	}
+	a, err := SimHash(maps.All(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := SimHash(maps.All(near))
+	c, _ := SimHash(maps.All(other))
+	if s := SimHashSimilarity(a, b); s < 0.9 {
+		t.Errorf("expected near-duplicate similarity, got %.2f", s)
+	}
+	if s := SimHashSimilarity(a, c); s > 0.8 {
+		t.Errorf("expected low similarity, got %.2f", s)
+	}
+	if SimHashSimilarity(a, a) != 1 || SimHashSimilarity(0, ^uint64(0)) != 0 {
+		t.Error("unexpected similarity bounds")
+	}
+	// A heavily weighted element dominates the fingerprint.
+	heavy := maps.Clone(doc)
+	heavy["spam"] = 1e6
+	h, _ := SimHash(maps.All(heavy))
+	s, _ := SimHash(maps.All(map[string]float64{"spam": 1}))
+	if h != s {
+		t.Errorf("expected heavy element to dominate: %x vs %x", h, s)
+	}
+	type pair struct{ x int }
+	if _, err := SimHash(maps.All(map[pair]float64{{1}: 1})); err == nil {
+		t.Error("expected error for unhashable element")
+	}
+}
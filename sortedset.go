@@ -0,0 +1,747 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math/bits"
+	"strings"
+)
+
+// SortedSet is an ordered set backed by an AVL tree, so that Add, Delete,
+// and Contains are O(log n) and iteration is always in ascending order
+// with no need to ToSlice and sort.
+// A SortedSet must be made with [NewSorted] or [NewSortedFunc] and must not
+// be modified while it is being iterated.
+type SortedSet[E comparable] struct {
+	root    *sortedNode[E]
+	compare func(a, b E) int
+}
+
+type sortedNode[E comparable] struct {
+	element     E
+	left, right *sortedNode[E]
+	height      int // of the subtree rooted here
+	size        int // number of elements in the subtree rooted here
+}
+
+// NewSorted returns a new SortedSet containing the given elements (if
+// any). If no elements are given, the type must be specified since it
+// can't be inferred.
+func NewSorted[E cmp.Ordered](elements ...E) SortedSet[E] {
+	return NewSortedFunc(cmp.Compare[E], elements...)
+}
+
+// NewSortedFromSorted returns a new SortedSet containing the given
+// elements, which must already be in strictly ascending order (i.e.,
+// sorted and without duplicates). The SortedSet is built bottom-up in O(n)
+// time, which is much faster than adding the elements one at a time.
+// When built with -tags setdebug the order is checked.
+func NewSortedFromSorted[E cmp.Ordered](elements []E) SortedSet[E] {
+	set := SortedSet[E]{compare: cmp.Compare[E]}
+	return set.fromSorted(elements)
+}
+
+// NewSortedFunc returns a new SortedSet ordered by the given comparison
+// function and containing the given elements (if any). The compare
+// function must return a negative number if a < b, a positive number if
+// a > b, and 0 if they are equal—and elements it considers equal are
+// treated as the same element, e.g., with a case-insensitive comparison
+// only the first of "x" and "X" to be added is kept. This allows
+// SortedSets of types that aren't cmp.Ordered, e.g., structs.
+func NewSortedFunc[E comparable](compare func(a, b E) int,
+	elements ...E) SortedSet[E] {
+	set := SortedSet[E]{compare: compare}
+	set.Add(elements...)
+	return set
+}
+
+// Add adds the given element(s) to the SortedSet.
+func (me *SortedSet[E]) Add(elements ...E) {
+	if me.compare == nil {
+		panic("set: SortedSet must be made with NewSorted or NewSortedFunc")
+	}
+	for _, element := range elements {
+		me.root, _ = me.root.insert(me.compare, element)
+	}
+	if debug {
+		me.checkInvariants()
+	}
+}
+
+// Delete deletes the given element(s) from the SortedSet.
+func (me *SortedSet[E]) Delete(elements ...E) {
+	for _, element := range elements {
+		me.root, _ = me.root.remove(me.compare, element)
+	}
+	if debug {
+		me.checkInvariants()
+	}
+}
+
+// DeleteRange deletes the elements that are >= lo and < hi from the
+// SortedSet, e.g., to expire all the keys older than a cutoff, and returns
+// how many were deleted. It takes O(log n) time however many elements are
+// deleted, since it splits the tree at lo and hi and joins the outer
+// parts rather than deleting elements one at a time.
+func (me *SortedSet[E]) DeleteRange(lo, hi E) int {
+	if me.compare(lo, hi) >= 0 {
+		return 0
+	}
+	below, rest := me.root.split(me.compare, lo)
+	middle, above := rest.split(me.compare, hi)
+	me.root = below.join(above)
+	if debug {
+		me.checkInvariants()
+	}
+	return middle.len()
+}
+
+// Clear deletes all the elements in the SortedSet.
+func (me *SortedSet[E]) Clear() { me.root = nil }
+
+// Len returns the number of elements in the SortedSet.
+func (me *SortedSet[E]) Len() int { return me.root.len() }
+
+// IsEmpty returns true if there are no elements in the SortedSet;
+// otherwise returns false.
+func (me *SortedSet[E]) IsEmpty() bool { return me.root == nil }
+
+// Contains returns true if element is in the SortedSet; otherwise returns
+// false.
+func (me *SortedSet[E]) Contains(element E) bool {
+	return me.root.find(me.compare, element) != nil
+}
+
+// Find returns the least element of this SortedSet for which pred returns
+// true and true; or the zero value and false if there is no such element.
+func (me *SortedSet[E]) Find(pred func(E) bool) (E, bool) {
+	for element := range me.All() {
+		if pred(element) {
+			return element, true
+		}
+	}
+	var zero E
+	return zero, false
+}
+
+// Min returns the least element in the SortedSet and true; or the zero
+// value and false if the SortedSet is empty.
+func (me *SortedSet[E]) Min() (E, bool) {
+	node := me.root
+	if node == nil {
+		var zero E
+		return zero, false
+	}
+	for node.left != nil {
+		node = node.left
+	}
+	return node.element, true
+}
+
+// Max returns the greatest element in the SortedSet and true; or the zero
+// value and false if the SortedSet is empty.
+func (me *SortedSet[E]) Max() (E, bool) {
+	node := me.root
+	if node == nil {
+		var zero E
+		return zero, false
+	}
+	for node.right != nil {
+		node = node.right
+	}
+	return node.element, true
+}
+
+// PopMin deletes the least element from the SortedSet and returns it and
+// true; or returns the zero value and false if the SortedSet is empty.
+// Together with [SortedSet.Add] this allows a SortedSet to be used as a
+// priority queue (of unique elements).
+func (me *SortedSet[E]) PopMin() (E, bool) {
+	if me.root == nil {
+		var zero E
+		return zero, false
+	}
+	var least *sortedNode[E]
+	me.root, least = me.root.removeMin()
+	if debug {
+		me.checkInvariants()
+	}
+	return least.element, true
+}
+
+// PopMax deletes the greatest element from the SortedSet and returns it
+// and true; or returns the zero value and false if the SortedSet is empty.
+func (me *SortedSet[E]) PopMax() (E, bool) {
+	if me.root == nil {
+		var zero E
+		return zero, false
+	}
+	var greatest *sortedNode[E]
+	me.root, greatest = me.root.removeMax()
+	if debug {
+		me.checkInvariants()
+	}
+	return greatest.element, true
+}
+
+// Floor returns the greatest element in the SortedSet that is <= x and
+// true; or the zero value and false if there is no such element.
+func (me *SortedSet[E]) Floor(x E) (E, bool) {
+	return me.root.predecessor(me.compare, x, true)
+}
+
+// Lower returns the greatest element in the SortedSet that is < x and
+// true; or the zero value and false if there is no such element.
+func (me *SortedSet[E]) Lower(x E) (E, bool) {
+	return me.root.predecessor(me.compare, x, false)
+}
+
+// Ceiling returns the least element in the SortedSet that is >= x and
+// true; or the zero value and false if there is no such element.
+func (me *SortedSet[E]) Ceiling(x E) (E, bool) {
+	return me.root.successor(me.compare, x, true)
+}
+
+// Higher returns the least element in the SortedSet that is > x and true;
+// or the zero value and false if there is no such element.
+func (me *SortedSet[E]) Higher(x E) (E, bool) {
+	return me.root.successor(me.compare, x, false)
+}
+
+// Rank returns the number of elements in the SortedSet that are less than
+// x (which need not be in the SortedSet), in O(log n) time.
+// See also [SortedSet.At].
+func (me *SortedSet[E]) Rank(x E) int {
+	rank := 0
+	for node := me.root; node != nil; {
+		if me.compare(x, node.element) <= 0 {
+			node = node.left
+		} else {
+			rank += node.left.len() + 1
+			node = node.right
+		}
+	}
+	return rank
+}
+
+// At returns the i-th smallest element (counting from 0) in the SortedSet
+// and true in O(log n) time; or the zero value and false if i is out of
+// range. For example, the median is at Len()/2.
+// See also [SortedSet.Rank].
+func (me *SortedSet[E]) At(i int) (E, bool) {
+	if i < 0 || i >= me.Len() {
+		var zero E
+		return zero, false
+	}
+	node := me.root
+	for {
+		switch left := node.left.len(); {
+		case i < left:
+			node = node.left
+		case i > left:
+			i -= left + 1
+			node = node.right
+		default:
+			return node.element, true
+		}
+	}
+}
+
+// Difference returns a new SortedSet that contains the elements which are
+// in this SortedSet that are not in the other SortedSet (which must have
+// the same ordering). This takes O(m + n) time, or O(m log n) if this
+// SortedSet is much smaller than the other.
+func (me *SortedSet[E]) Difference(other *SortedSet[E]) SortedSet[E] {
+	diff := make([]E, 0, me.Len())
+	if probeCheaper(me.Len(), other.Len()) {
+		for element := range me.All() {
+			if !other.Contains(element) {
+				diff = append(diff, element)
+			}
+		}
+	} else {
+		b := other.ToSlice()
+		j := 0
+		for element := range me.All() {
+			for j < len(b) && me.compare(b[j], element) < 0 {
+				j++
+			}
+			if j == len(b) || me.compare(b[j], element) != 0 {
+				diff = append(diff, element)
+			}
+		}
+	}
+	return me.fromSorted(diff)
+}
+
+// Intersection returns a new SortedSet that contains the elements this
+// SortedSet has in common with the other SortedSet (which must have the
+// same ordering). This takes O(m + n) time, or O(m log n) if one
+// SortedSet is much smaller than the other.
+func (me *SortedSet[E]) Intersection(other *SortedSet[E]) SortedSet[E] {
+	small, large := me, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+	intersection := make([]E, 0, small.Len())
+	if probeCheaper(small.Len(), large.Len()) {
+		for element := range small.All() {
+			if large.Contains(element) {
+				intersection = append(intersection, element)
+			}
+		}
+	} else {
+		b := large.ToSlice()
+		j := 0
+		for element := range small.All() {
+			for j < len(b) && me.compare(b[j], element) < 0 {
+				j++
+			}
+			if j < len(b) && me.compare(b[j], element) == 0 {
+				intersection = append(intersection, element)
+			}
+		}
+	}
+	return me.fromSorted(intersection)
+}
+
+// Union returns a new SortedSet that contains the elements from this
+// SortedSet and from the other SortedSet (which must have the same
+// ordering). This takes O(m + n) time.
+func (me *SortedSet[E]) Union(other *SortedSet[E]) SortedSet[E] {
+	a, b := me.ToSlice(), other.ToSlice()
+	union := make([]E, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch c := me.compare(a[i], b[j]); {
+		case c < 0:
+			union = append(union, a[i])
+			i++
+		case c > 0:
+			union = append(union, b[j])
+			j++
+		default:
+			union = append(union, a[i])
+			i++
+			j++
+		}
+	}
+	union = append(append(union, a[i:]...), b[j:]...)
+	return me.fromSorted(union)
+}
+
+// probeCheaper returns true if looking up each of m elements in a tree of
+// n elements is cheaper than merging the two in order.
+func probeCheaper(m, n int) bool {
+	return m*bits.Len(uint(n)) < m+n
+}
+
+// fromSorted returns a new SortedSet with this SortedSet's ordering that
+// contains the given elements, which must be in ascending order and
+// unique.
+func (me *SortedSet[E]) fromSorted(elements []E) SortedSet[E] {
+	set := SortedSet[E]{root: buildSorted(elements), compare: me.compare}
+	if debug {
+		set.checkInvariants()
+	}
+	return set
+}
+
+// Clone returns a copy of this SortedSet.
+func (me *SortedSet[E]) Clone() SortedSet[E] {
+	return SortedSet[E]{root: me.root.clone(), compare: me.compare}
+}
+
+// Equal returns true if this SortedSet has the same elements as the other
+// SortedSet (which must have the same ordering), as determined by this
+// SortedSet's comparison function; otherwise returns false.
+func (me *SortedSet[E]) Equal(other *SortedSet[E]) bool {
+	if me.Len() != other.Len() {
+		return false
+	}
+	next, stop := iter.Pull(other.All())
+	defer stop()
+	for element := range me.All() {
+		if x, _ := next(); me.compare(x, element) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+// The elements are in ascending order.
+func (me *SortedSet[E]) All() iter.Seq[E] {
+	return func(yield func(E) bool) { me.root.ascend(yield) }
+}
+
+// Backward returns an iterator over the SortedSet's elements in descending
+// order, e.g., for element := range aset.Backward() ...
+func (me *SortedSet[E]) Backward() iter.Seq[E] {
+	return func(yield func(E) bool) { me.root.descend(yield) }
+}
+
+// Range returns an iterator over the SortedSet's elements that are >= lo
+// and < hi, in ascending order. Only the relevant parts of the tree are
+// visited.
+// See also [SortedSet.RangeBackward] and [SortedSet.View].
+func (me *SortedSet[E]) Range(lo, hi E) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		me.root.ascendRange(me.compare, lo, hi, yield)
+	}
+}
+
+// RangeBackward returns an iterator over the SortedSet's elements that are
+// >= lo and < hi, in descending order, e.g., to get the latest few keys
+// before a cutoff.
+// See also [SortedSet.Range] and [SortedSet.View].
+func (me *SortedSet[E]) RangeBackward(lo, hi E) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		me.root.descendRange(me.compare, lo, hi, yield)
+	}
+}
+
+// ToSlice returns this SortedSet's elements as a sorted slice.
+func (me *SortedSet[E]) ToSlice() []E {
+	slice := make([]E, 0, me.Len())
+	for element := range me.All() {
+		slice = append(slice, element)
+	}
+	return slice
+}
+
+// ToSet returns a copy of this SortedSet's elements as a plain Set.
+func (me *SortedSet[E]) ToSet() Set[E] {
+	set := Set[E]{set: make(map[E]struct{}, me.Len())}
+	for element := range me.All() {
+		set.set[element] = struct{}{}
+	}
+	return set
+}
+
+// String returns a human readable string representation of the SortedSet
+// with its elements in ascending order.
+func (me *SortedSet[E]) String() string { return sortedString(me.All()) }
+
+// sortedString returns a human readable string representation of the
+// elements in the order given.
+func sortedString[E comparable](elements iter.Seq[E]) string {
+	var zero E
+	format := "%s%v"
+	if _, ok := any(zero).(string); ok {
+		format = "%s%q"
+	}
+	var out strings.Builder
+	out.WriteByte('{')
+	sep := ""
+	for element := range elements {
+		fmt.Fprintf(&out, format, sep, element)
+		sep = " "
+	}
+	out.WriteByte('}')
+	return out.String()
+}
+
+// buildSorted returns the root of a perfectly balanced tree of the given
+// elements (which must be in ascending order and unique) in O(n) time.
+// The nodes are allocated together, which is much faster than one at a
+// time, at the cost that their memory is only released once all of them
+// have been deleted.
+func buildSorted[E comparable](elements []E) *sortedNode[E] {
+	nodes := make([]sortedNode[E], len(elements))
+	var build func(lo, hi int) *sortedNode[E]
+	build = func(lo, hi int) *sortedNode[E] {
+		if lo >= hi {
+			return nil
+		}
+		mid := lo + (hi-lo)/2
+		node := &nodes[mid]
+		node.element = elements[mid]
+		node.left, node.right = build(lo, mid), build(mid+1, hi)
+		node.update()
+		return node
+	}
+	return build(0, len(elements))
+}
+
+func (me *sortedNode[E]) len() int {
+	if me == nil {
+		return 0
+	}
+	return me.size
+}
+
+func (me *sortedNode[E]) depth() int {
+	if me == nil {
+		return 0
+	}
+	return me.height
+}
+
+func (me *sortedNode[E]) find(compare func(a, b E) int,
+	element E) *sortedNode[E] {
+	for me != nil {
+		switch c := compare(element, me.element); {
+		case c < 0:
+			me = me.left
+		case c > 0:
+			me = me.right
+		default:
+			return me
+		}
+	}
+	return nil
+}
+
+// predecessor returns the greatest element < x (or <= x if inclusive).
+func (me *sortedNode[E]) predecessor(compare func(a, b E) int, x E,
+	inclusive bool) (E, bool) {
+	var found *sortedNode[E]
+	for me != nil {
+		c := compare(me.element, x)
+		if c < 0 || (inclusive && c == 0) {
+			found = me
+			me = me.right
+		} else {
+			me = me.left
+		}
+	}
+	if found == nil {
+		var zero E
+		return zero, false
+	}
+	return found.element, true
+}
+
+// successor returns the least element > x (or >= x if inclusive).
+func (me *sortedNode[E]) successor(compare func(a, b E) int, x E,
+	inclusive bool) (E, bool) {
+	var found *sortedNode[E]
+	for me != nil {
+		c := compare(me.element, x)
+		if c > 0 || (inclusive && c == 0) {
+			found = me
+			me = me.left
+		} else {
+			me = me.right
+		}
+	}
+	if found == nil {
+		var zero E
+		return zero, false
+	}
+	return found.element, true
+}
+
+func (me *sortedNode[E]) ascend(yield func(E) bool) bool {
+	return me == nil || (me.left.ascend(yield) && yield(me.element) &&
+		me.right.ascend(yield))
+}
+
+func (me *sortedNode[E]) descend(yield func(E) bool) bool {
+	return me == nil || (me.right.descend(yield) && yield(me.element) &&
+		me.left.descend(yield))
+}
+
+func (me *sortedNode[E]) ascendRange(compare func(a, b E) int, lo, hi E,
+	yield func(E) bool) bool {
+	if me == nil {
+		return true
+	}
+	above := compare(me.element, lo) >= 0
+	below := compare(me.element, hi) < 0
+	if above && !me.left.ascendRange(compare, lo, hi, yield) {
+		return false
+	}
+	if above && below && !yield(me.element) {
+		return false
+	}
+	return !below || me.right.ascendRange(compare, lo, hi, yield)
+}
+
+func (me *sortedNode[E]) descendRange(compare func(a, b E) int, lo,
+	hi E, yield func(E) bool) bool {
+	if me == nil {
+		return true
+	}
+	above := compare(me.element, lo) >= 0
+	below := compare(me.element, hi) < 0
+	if below && !me.right.descendRange(compare, lo, hi, yield) {
+		return false
+	}
+	if above && below && !yield(me.element) {
+		return false
+	}
+	return !above || me.left.descendRange(compare, lo, hi, yield)
+}
+
+func (me *sortedNode[E]) clone() *sortedNode[E] {
+	if me == nil {
+		return nil
+	}
+	node := *me
+	node.left, node.right = me.left.clone(), me.right.clone()
+	return &node
+}
+
+// insert returns the subtree's new root and true if element was added or
+// false if it was already present.
+func (me *sortedNode[E]) insert(compare func(a, b E) int,
+	element E) (*sortedNode[E], bool) {
+	if me == nil {
+		return &sortedNode[E]{element: element, height: 1, size: 1}, true
+	}
+	var added bool
+	switch c := compare(element, me.element); {
+	case c < 0:
+		me.left, added = me.left.insert(compare, element)
+	case c > 0:
+		me.right, added = me.right.insert(compare, element)
+	default:
+		return me, false
+	}
+	if !added {
+		return me, false
+	}
+	return me.rebalance(), true
+}
+
+// remove returns the subtree's new root and true if element was deleted or
+// false if it wasn't present.
+func (me *sortedNode[E]) remove(compare func(a, b E) int,
+	element E) (*sortedNode[E], bool) {
+	if me == nil {
+		return nil, false
+	}
+	var removed bool
+	switch c := compare(element, me.element); {
+	case c < 0:
+		me.left, removed = me.left.remove(compare, element)
+	case c > 0:
+		me.right, removed = me.right.remove(compare, element)
+	default:
+		if me.left == nil {
+			return me.right, true
+		}
+		if me.right == nil {
+			return me.left, true
+		}
+		var least *sortedNode[E]
+		me.right, least = me.right.removeMin()
+		least.left, least.right = me.left, me.right
+		return least.rebalance(), true
+	}
+	if !removed {
+		return me, false
+	}
+	return me.rebalance(), true
+}
+
+// removeMin returns the subtree's new root and the (detached) node that
+// held its least element.
+func (me *sortedNode[E]) removeMin() (*sortedNode[E], *sortedNode[E]) {
+	if me.left == nil {
+		return me.right, me
+	}
+	var least *sortedNode[E]
+	me.left, least = me.left.removeMin()
+	return me.rebalance(), least
+}
+
+// removeMax returns the subtree's new root and the (detached) node that
+// held its greatest element.
+func (me *sortedNode[E]) removeMax() (*sortedNode[E], *sortedNode[E]) {
+	if me.right == nil {
+		return me.left, me
+	}
+	var greatest *sortedNode[E]
+	me.right, greatest = me.right.removeMax()
+	return me.rebalance(), greatest
+}
+
+// split returns a tree of the elements < x and a tree of those >= x.
+func (me *sortedNode[E]) split(compare func(a, b E) int,
+	x E) (*sortedNode[E], *sortedNode[E]) {
+	if me == nil {
+		return nil, nil
+	}
+	if compare(x, me.element) <= 0 {
+		below, above := me.left.split(compare, x)
+		return below, join3(above, me, me.right)
+	}
+	below, above := me.right.split(compare, x)
+	return join3(me.left, me, below), above
+}
+
+// join returns a tree of the elements of this tree and of other, all of
+// whose elements must be greater than this tree's.
+func (me *sortedNode[E]) join(other *sortedNode[E]) *sortedNode[E] {
+	if me == nil {
+		return other
+	}
+	if other == nil {
+		return me
+	}
+	rest, least := other.removeMin()
+	return join3(me, least, rest)
+}
+
+// join3 returns a balanced tree of the elements of left, of node, and of
+// right, where left's are all less than node's and right's all greater,
+// in time proportional to the difference in their heights.
+func join3[E comparable](left, node, right *sortedNode[E]) *sortedNode[E] {
+	switch {
+	case left.depth() > right.depth()+1:
+		left.right = join3(left.right, node, right)
+		return left.rebalance()
+	case right.depth() > left.depth()+1:
+		right.left = join3(left, node, right.left)
+		return right.rebalance()
+	}
+	node.left, node.right = left, right
+	node.update()
+	return node
+}
+
+func (me *sortedNode[E]) update() {
+	me.height = 1 + max(me.left.depth(), me.right.depth())
+	me.size = 1 + me.left.len() + me.right.len()
+}
+
+// rebalance restores the AVL property at this node (whose subtrees must
+// already be balanced) and returns the subtree's new root.
+func (me *sortedNode[E]) rebalance() *sortedNode[E] {
+	me.update()
+	switch balance := me.left.depth() - me.right.depth(); {
+	case balance > 1:
+		if me.left.left.depth() < me.left.right.depth() {
+			me.left = me.left.rotateLeft()
+		}
+		return me.rotateRight()
+	case balance < -1:
+		if me.right.right.depth() < me.right.left.depth() {
+			me.right = me.right.rotateRight()
+		}
+		return me.rotateLeft()
+	}
+	return me
+}
+
+func (me *sortedNode[E]) rotateLeft() *sortedNode[E] {
+	root := me.right
+	me.right, root.left = root.left, me
+	me.update()
+	root.update()
+	return root
+}
+
+func (me *sortedNode[E]) rotateRight() *sortedNode[E] {
+	root := me.left
+	me.left, root.right = root.right, me
+	me.update()
+	root.update()
+	return root
+}
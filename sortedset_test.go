@@ -0,0 +1,318 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortedSet(t *testing.T) {
+	s := NewSorted(5, 3, 9, 1, 3, 7)
+	check(s.String(), s.Len(), "{1 3 5 7 9}", 5, t)
+	if !s.Contains(7) || s.Contains(4) {
+		t.Error("unexpected Contains result")
+	}
+	s.Delete(3, 4, 9)
+	check(s.String(), s.Len(), "{1 5 7}", 3, t)
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Error("expected empty")
+	}
+	z := NewSorted[string]()
+	z.Add("pear", "apple", "fig")
+	check(z.String(), z.Len(), `{"apple" "fig" "pear"}`, 3, t)
+}
+
+func TestSortedSetLarge(t *testing.T) {
+	s := NewSorted[int]()
+	for i := range 1000 {
+		s.Add((i * 7919) % 1000)
+	}
+	if s.Len() != 1000 {
+		t.Fatalf("expected 1000 elements, got %d", s.Len())
+	}
+	if !slices.IsSorted(s.ToSlice()) {
+		t.Error("expected ascending order")
+	}
+	if h := s.root.height; h > 15 {
+		t.Errorf("tree too tall: %d", h)
+	}
+	for i := 0; i < 1000; i += 2 {
+		s.Delete(i)
+	}
+	s.checkInvariants()
+	if s.Len() != 500 || s.Contains(10) || !s.Contains(11) {
+		t.Errorf("unexpected result after deletes: %d", s.Len())
+	}
+}
+
+func TestSortedSetAlgebra(t *testing.T) {
+	a := NewSorted(1, 2, 3, 4, 5)
+	b := NewSorted(4, 5, 6, 7)
+	u := a.Union(&b)
+	check(u.String(), u.Len(), "{1 2 3 4 5 6 7}", 7, t)
+	x := a.Intersection(&b)
+	check(x.String(), x.Len(), "{4 5}", 2, t)
+	d := a.Difference(&b)
+	check(d.String(), d.Len(), "{1 2 3}", 3, t)
+	c := a.Clone()
+	c.Add(9)
+	if a.Contains(9) || a.Equal(&c) {
+		t.Error("expected clone to be independent")
+	}
+	c.Delete(9)
+	if !a.Equal(&c) {
+		t.Errorf("expected %v == %v", a, c)
+	}
+	s := a.ToSet()
+	check(sortedStr(s), s.Len(), "{1 2 3 4 5}", 5, t)
+}
+
+func TestSortedSetMinMax(t *testing.T) {
+	s := NewSorted[int]()
+	if _, ok := s.Min(); ok {
+		t.Error("expected no Min")
+	}
+	if _, ok := s.PopMax(); ok {
+		t.Error("expected no PopMax")
+	}
+	s.Add(50, 20, 80, 10, 30, 70, 90)
+	if x, ok := s.Min(); !ok || x != 10 {
+		t.Errorf("expected Min 10, got %d", x)
+	}
+	if x, ok := s.Max(); !ok || x != 90 {
+		t.Errorf("expected Max 90, got %d", x)
+	}
+	var popped []int
+	for !s.IsEmpty() {
+		lo, _ := s.PopMin()
+		popped = append(popped, lo)
+		if hi, ok := s.PopMax(); ok {
+			popped = append(popped, hi)
+		}
+		s.checkInvariants()
+	}
+	if !slices.Equal(popped, []int{10, 90, 20, 80, 30, 70, 50}) {
+		t.Errorf("unexpected pop order %v", popped)
+	}
+}
+
+func TestSortedSetNeighbours(t *testing.T) {
+	s := NewSorted(10, 20, 30, 40)
+	for _, c := range []struct {
+		name string
+		f    func(int) (int, bool)
+		x    int
+		exp  int
+		ok   bool
+	}{
+		{"Floor", s.Floor, 25, 20, true},
+		{"Floor", s.Floor, 30, 30, true},
+		{"Floor", s.Floor, 5, 0, false},
+		{"Lower", s.Lower, 30, 20, true},
+		{"Lower", s.Lower, 10, 0, false},
+		{"Ceiling", s.Ceiling, 25, 30, true},
+		{"Ceiling", s.Ceiling, 40, 40, true},
+		{"Ceiling", s.Ceiling, 41, 0, false},
+		{"Higher", s.Higher, 20, 30, true},
+		{"Higher", s.Higher, 40, 0, false},
+	} {
+		if act, ok := c.f(c.x); act != c.exp || ok != c.ok {
+			t.Errorf("%s(%d): expected %d %t, got %d %t", c.name, c.x,
+				c.exp, c.ok, act, ok)
+		}
+	}
+}
+
+func TestSortedSetRankAt(t *testing.T) {
+	s := NewSorted[int]()
+	for i := range 100 {
+		s.Add(i * 10)
+	}
+	for i := range 100 {
+		if r := s.Rank(i * 10); r != i {
+			t.Errorf("Rank(%d): expected %d, got %d", i*10, i, r)
+		}
+		if x, ok := s.At(i); !ok || x != i*10 {
+			t.Errorf("At(%d): expected %d, got %d", i, i*10, x)
+		}
+	}
+	if r := s.Rank(15); r != 2 {
+		t.Errorf("Rank(15): expected 2, got %d", r)
+	}
+	if r := s.Rank(-1); r != 0 {
+		t.Errorf("Rank(-1): expected 0, got %d", r)
+	}
+	if r := s.Rank(5000); r != 100 {
+		t.Errorf("Rank(5000): expected 100, got %d", r)
+	}
+	for _, i := range []int{-1, 100} {
+		if _, ok := s.At(i); ok {
+			t.Errorf("At(%d): expected out of range", i)
+		}
+	}
+}
+
+func TestSortedSetBackwardRange(t *testing.T) {
+	s := NewSorted[int]()
+	for i := range 20 {
+		s.Add(i * 5)
+	}
+	backward := slices.Collect(s.Backward())
+	exp := s.ToSlice()
+	slices.Reverse(exp)
+	if !slices.Equal(backward, exp) {
+		t.Errorf("expected %v, got %v", exp, backward)
+	}
+	if r := slices.Collect(s.Range(12, 30)); !slices.Equal(r,
+		[]int{15, 20, 25}) {
+		t.Errorf("unexpected Range %v", r)
+	}
+	if r := slices.Collect(s.RangeBackward(10, 30)); !slices.Equal(r,
+		[]int{25, 20, 15, 10}) {
+		t.Errorf("unexpected RangeBackward %v", r)
+	}
+	if r := slices.Collect(s.Range(30, 30)); len(r) != 0 {
+		t.Errorf("expected empty Range, got %v", r)
+	}
+	var latest []int
+	for x := range s.Backward() {
+		if len(latest) == 3 {
+			break
+		}
+		latest = append(latest, x)
+	}
+	if !slices.Equal(latest, []int{95, 90, 85}) {
+		t.Errorf("unexpected latest %v", latest)
+	}
+}
+
+func TestSortedSetFunc(t *testing.T) {
+	type point struct{ x, y int }
+	byXY := func(a, b point) int {
+		if c := cmp.Compare(a.x, b.x); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.y, b.y)
+	}
+	p := NewSortedFunc(byXY, point{2, 1}, point{1, 5}, point{1, 2})
+	check(p.String(), p.Len(), "{{1 2} {1 5} {2 1}}", 3, t)
+	if x, ok := p.Floor(point{1, 9}); !ok || x != (point{1, 5}) {
+		t.Errorf("unexpected Floor %v", x)
+	}
+	fold := func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+	s := NewSortedFunc(fold, "b", "A", "a", "C")
+	check(s.String(), s.Len(), `{"A" "b" "C"}`, 3, t)
+	if !s.Contains("c") {
+		t.Error("expected case-insensitive Contains")
+	}
+	u := NewSortedFunc(fold, "a", "B", "c")
+	if !s.Equal(&u) {
+		t.Errorf("expected %v == %v", s, u)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for zero value SortedSet")
+		}
+	}()
+	var z SortedSet[int]
+	z.Add(1)
+}
+
+func TestSortedSetMergeAlgebra(t *testing.T) {
+	// Similar sizes use merging; very different sizes use probing.
+	a, b := NewSorted[int](), NewSorted[int]()
+	for i := range 300 {
+		a.Add(i * 2)
+		b.Add(i * 3)
+	}
+	tiny := NewSorted(0, 3, 4, 1000)
+	for _, c := range []struct {
+		name string
+		x, y *SortedSet[int]
+	}{{"merge", &a, &b}, {"probe", &tiny, &a}, {"probe", &a, &tiny}} {
+		xs, ys := c.x.ToSet(), c.y.ToSet()
+		for _, op := range []struct {
+			name   string
+			sorted SortedSet[int]
+			plain  Set[int]
+		}{
+			{"Union", c.x.Union(c.y), xs.Union(ys)},
+			{"Intersection", c.x.Intersection(c.y), xs.Intersection(ys)},
+			{"Difference", c.x.Difference(c.y), xs.Difference(ys)},
+		} {
+			op.sorted.checkInvariants()
+			act := op.sorted.ToSlice()
+			if exp := slices.Sorted(op.plain.All()); !slices.Equal(act,
+				exp) {
+				t.Errorf("%s %s: expected %v, got %v", c.name, op.name,
+					exp, act)
+			}
+		}
+	}
+}
+
+func TestNewSortedFromSorted(t *testing.T) {
+	elements := make([]int, 1000)
+	for i := range elements {
+		elements[i] = i * 3
+	}
+	s := NewSortedFromSorted(elements)
+	s.checkInvariants()
+	if s.Len() != 1000 || !slices.Equal(s.ToSlice(), elements) {
+		t.Errorf("unexpected SortedSet of %d", s.Len())
+	}
+	s.Add(1)
+	s.Delete(0, 3, 6)
+	s.checkInvariants()
+	if x, ok := s.Min(); !ok || x != 1 {
+		t.Errorf("expected Min 1, got %d", x)
+	}
+	e := NewSortedFromSorted([]string{})
+	check(e.String(), e.Len(), "{}", 0, t)
+}
+
+func TestNewSortedFromUnsorted(t *testing.T) {
+	if !debug {
+		t.Skip("the order is only checked with -tags setdebug")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for unsorted input")
+		}
+	}()
+	NewSortedFromSorted([]int{1, 3, 2})
+}
+
+func TestSortedSetDeleteRange(t *testing.T) {
+	for _, c := range []struct {
+		lo, hi, deleted int
+	}{{100, 200, 100}, {-5, 10, 10}, {990, 2000, 10}, {5, 5, 0},
+		{7, 3, 0}, {-10, 2000, 1000}} {
+		s := NewSorted[int]()
+		for i := range 1000 {
+			s.Add(i)
+		}
+		if n := s.DeleteRange(c.lo, c.hi); n != c.deleted {
+			t.Errorf("[%d, %d): expected %d deleted, got %d", c.lo, c.hi,
+				c.deleted, n)
+		}
+		s.checkInvariants()
+		if s.Len() != 1000-c.deleted {
+			t.Errorf("[%d, %d): expected %d left, got %d", c.lo, c.hi,
+				1000-c.deleted, s.Len())
+		}
+		for x := range s.All() {
+			if x >= c.lo && x < c.hi {
+				t.Errorf("[%d, %d): %d not deleted", c.lo, c.hi, x)
+			}
+		}
+		if c.deleted == 100 && (!s.Contains(99) || !s.Contains(200)) {
+			t.Errorf("[%d, %d): deleted too much", c.lo, c.hi)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "iter"
+
+// SortedView is a live read-only view of the elements of a [SortedSet]
+// that are >= lo and < hi, e.g., for a time window or the strings with a
+// given prefix. It holds no elements of its own, so it is cheap to make
+// and always reflects the SortedSet's current contents. Make one with
+// [SortedSet.View]; to delete the elements in the view use
+// [SortedSet.DeleteRange].
+type SortedView[E comparable] struct {
+	set    *SortedSet[E]
+	lo, hi E
+}
+
+// View returns a [SortedView] of this SortedSet's elements that are >= lo
+// and < hi.
+func (me *SortedSet[E]) View(lo, hi E) SortedView[E] {
+	return SortedView[E]{me, lo, hi}
+}
+
+// Len returns the number of elements in the SortedView, in O(log n) time.
+func (me SortedView[E]) Len() int {
+	if me.set.compare(me.lo, me.hi) >= 0 {
+		return 0
+	}
+	return me.set.Rank(me.hi) - me.set.Rank(me.lo)
+}
+
+// IsEmpty returns true if there are no elements in the SortedView;
+// otherwise returns false.
+func (me SortedView[E]) IsEmpty() bool {
+	element, ok := me.set.Ceiling(me.lo)
+	return !ok || me.set.compare(element, me.hi) >= 0
+}
+
+// Contains returns true if element is in the SortedView (i.e., in range
+// and in the SortedSet); otherwise returns false.
+func (me SortedView[E]) Contains(element E) bool {
+	return me.set.compare(element, me.lo) >= 0 &&
+		me.set.compare(element, me.hi) < 0 && me.set.Contains(element)
+}
+
+// All returns an iterator over the SortedView's elements in ascending
+// order, e.g., for element := range view.All() ...
+func (me SortedView[E]) All() iter.Seq[E] {
+	return me.set.Range(me.lo, me.hi)
+}
+
+// Backward returns an iterator over the SortedView's elements in
+// descending order, e.g., for element := range view.Backward() ...
+func (me SortedView[E]) Backward() iter.Seq[E] {
+	return me.set.RangeBackward(me.lo, me.hi)
+}
+
+// ToSlice returns a copy of this SortedView's elements as a sorted slice.
+func (me SortedView[E]) ToSlice() []E {
+	slice := make([]E, 0, me.Len())
+	for element := range me.All() {
+		slice = append(slice, element)
+	}
+	return slice
+}
+
+// String returns a human readable string representation of the SortedView
+// with its elements in ascending order.
+func (me SortedView[E]) String() string { return sortedString(me.All()) }
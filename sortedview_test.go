@@ -0,0 +1,37 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSortedView(t *testing.T) {
+	s := NewSorted(10, 20, 30, 40, 50)
+	v := s.View(15, 45)
+	check(v.String(), v.Len(), "{20 30 40}", 3, t)
+	if !v.Contains(30) || v.Contains(10) || v.Contains(25) {
+		t.Error("unexpected Contains result")
+	}
+	if b := slices.Collect(v.Backward()); !slices.Equal(b,
+		[]int{40, 30, 20}) {
+		t.Errorf("unexpected Backward %v", b)
+	}
+	s.Add(25, 45, 5) // the view is live; 45 is out of range
+	check(v.String(), v.Len(), "{20 25 30 40}", 4, t)
+	if !v.Contains(25) || v.Contains(45) {
+		t.Error("expected the view to see the new elements")
+	}
+	s.DeleteRange(15, 45)
+	if !v.IsEmpty() || v.Len() != 0 || len(v.ToSlice()) != 0 {
+		t.Errorf("expected an empty view, got %v", v)
+	}
+	for _, e := range []SortedView[int]{s.View(30, 30), s.View(50, 10)} {
+		if !e.IsEmpty() || e.Len() != 0 {
+			t.Errorf("expected an empty view, got %v", e)
+		}
+	}
+	words := NewSorted("app", "apple", "apply", "banana")
+	prefix := words.View("appl", "appm")
+	check(prefix.String(), prefix.Len(), `{"apple" "apply"}`, 2, t)
+}
@@ -5,6 +5,7 @@ go mod tidy
 go fmt .
 staticcheck .
 go vet .
+GOARCH=386 go vet ./... # catches constants that overflow a 32-bit int
 golangci-lint run
 echo -n "go test . "
 go test .
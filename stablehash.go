@@ -0,0 +1,119 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// StableHashVersion identifies the algorithm used by [StableHash] (and so
+// by [Set.Digest] and [Set.SplitByHash]). It is only ever changed if the
+// algorithm changes, so values computed with the same version agree
+// between processes and machines.
+const StableHashVersion = 1
+
+// StableHash returns a 64-bit hash of the given element which, unlike
+// hash/maphash, is the same in every process and on every machine. It is
+// computed using FNV-1a over a canonical encoding of the element and so
+// is only available for elements that are encodable: booleans, integers,
+// floats, and strings (including named types based on these), and types
+// that implement encoding.BinaryMarshaler. For other types an error is
+// returned. Integers of different sizes but the same value hash the same.
+func StableHash[E comparable](element E) (uint64, error) {
+	raw, err := stableEncode(element)
+	if err != nil {
+		return 0, err
+	}
+	hasher := fnv.New64a()
+	hasher.Write(raw)
+	return hasher.Sum64(), nil
+}
+
+func stableEncode(element any) ([]byte, error) {
+	if marshaler, ok := element.(encoding.BinaryMarshaler); ok {
+		raw, err := marshaler.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{'m'}, raw...), nil
+	}
+	value := reflect.ValueOf(element)
+	switch value.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return []byte{'b', 1}, nil
+		}
+		return []byte{'b', 0}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return binary.BigEndian.AppendUint64([]byte{'i'},
+			uint64(value.Int())), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return binary.BigEndian.AppendUint64([]byte{'u'}, value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := value.Float()
+		if f == 0 {
+			f = 0 // -0 == +0 so they must hash the same
+		}
+		return binary.BigEndian.AppendUint64([]byte{'f'},
+			math.Float64bits(f)), nil
+	case reflect.String:
+		return append([]byte{'s'}, value.String()...), nil
+	}
+	return nil, fmt.Errorf("cannot stable hash element of type %T", element)
+}
+
+// Digest returns an order-independent 64-bit digest of the Set's elements
+// based on [StableHash], so two Sets with the same elements have the same
+// digest in any process on any machine. Returns an error if the elements
+// aren't stable hashable.
+func (me *Set[E]) Digest() (uint64, error) {
+	var digest uint64
+	for element := range me.set {
+		h, err := StableHash(element)
+		if err != nil {
+			return 0, err
+		}
+		digest += mix64(h)
+	}
+	return mix64(digest ^ uint64(len(me.set))), nil
+}
+
+// SplitByHash returns n new Sets (n must be > 0) which between them contain
+// all this Set's elements, with each element in the Set at index
+// [StableHash] % n, so elements are assigned to the same shard by every
+// process. Returns an error if the elements aren't stable hashable.
+func (me *Set[E]) SplitByHash(n int) ([]Set[E], error) {
+	if n < 1 {
+		return nil, fmt.Errorf("cannot split into %d sets", n)
+	}
+	shards := make([]Set[E], n)
+	for i := range shards {
+		shards[i] = Set[E]{set: make(map[E]struct{}, len(me.set)/n)}
+	}
+	for element := range me.set {
+		h, err := StableHash(element)
+		if err != nil {
+			return nil, err
+		}
+		shards[h%uint64(n)].set[element] = struct{}{}
+	}
+	return shards, nil
+}
+
+// mix64 is the splitmix64 finalizer; it spreads hash bits so that summing
+// hashes for a digest doesn't let similar hashes cancel out.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,105 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+type userID int
+
+func TestStableHash(t *testing.T) {
+	// These values must never change without a StableHashVersion bump.
+	for _, c := range []struct {
+		element any
+		exp     uint64
+	}{{"hello", 0xa8290f4fe4f22aaa}, {42, 0x5b379d7bc82bd2d2},
+		{true, 0x08a61307b54d99ac}} {
+		if h, err := StableHash(c.element); err != nil || h != c.exp {
+			t.Errorf("expected %#x for %v, got %#x %v", c.exp, c.element,
+				h, err)
+		}
+	}
+	a, _ := StableHash(42)
+	b, _ := StableHash(int8(42))
+	c, _ := StableHash(userID(42))
+	if a != b || a != c {
+		t.Errorf("expected equal hashes, got %x %x %x", a, b, c)
+	}
+	d, _ := StableHash(uint(42))
+	if a == d {
+		t.Errorf("expected signed and unsigned to differ, got %x", a)
+	}
+	z, _ := StableHash(0.0)
+	nz, _ := StableHash(math.Copysign(0, -1))
+	if z != nz {
+		t.Errorf("expected -0 and +0 to hash the same")
+	}
+	if _, err := StableHash(time.Date(2025, 1, 2, 3, 4, 5, 6,
+		time.UTC)); err != nil {
+		t.Errorf("expected BinaryMarshaler to be hashable, got %v", err)
+	}
+	type point struct{ x, y int }
+	if _, err := StableHash(point{1, 2}); err == nil {
+		t.Error("expected struct not to be stable hashable")
+	}
+}
+
+func TestDigest(t *testing.T) {
+	s := New(1, 2, 3, 4, 5)
+	u := New(5, 4, 3, 2, 1)
+	ds, err := s.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	du, _ := u.Digest()
+	if ds != du {
+		t.Errorf("expected equal digests, got %x %x", ds, du)
+	}
+	u.Delete(5)
+	if du, _ = u.Digest(); ds == du {
+		t.Error("expected different digests")
+	}
+	e := New[int]()
+	f := New(0)
+	de, _ := e.Digest()
+	df, _ := f.Digest()
+	if de == df {
+		t.Error("expected empty and {0} digests to differ")
+	}
+	type point struct{ x, y int }
+	p := New(point{1, 2})
+	if _, err := p.Digest(); err == nil {
+		t.Error("expected digest error")
+	}
+}
+
+func TestSplitByHash(t *testing.T) {
+	s := New[string]()
+	for _, x := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		s.Add(x)
+	}
+	shards, err := s.SplitByHash(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	all := New[string]()
+	n := 0
+	for i, shard := range shards {
+		n += shard.Len()
+		all.Unite(shard)
+		for x := range shard.All() {
+			h, _ := StableHash(x)
+			if int(h%3) != i {
+				t.Errorf("expected %q in shard %d, got %d", x, h%3, i)
+			}
+		}
+	}
+	if n != s.Len() || !all.Equal(s) {
+		t.Errorf("expected shards to partition %v", s)
+	}
+	if _, err := s.SplitByHash(0); err == nil {
+		t.Error("expected error for 0 shards")
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"time"
+)
+
+// StreamDeduper filters one or more streams so that only the first
+// occurrence of each element gets through. By default it remembers every
+// element seen, but it can be given a memory limit, in which case it keeps
+// a rotating pair of Sets (the current generation and the previous one)
+// and only guarantees to suppress a repeat that occurs within limit
+// distinct elements of the element's last occurrence ("exactly-once-ish").
+// See [OpenStreamDeduper] for persisting a StreamDeduper across restarts.
+// StreamDeduper is not safe for concurrent use.
+type StreamDeduper[E comparable] struct {
+	current    Set[E]
+	previous   Set[E]
+	limit      int
+	checkpoint *DedupCheckpoint[E] // nil unless opened for persistence
+	now        func() time.Time
+	saved      time.Time // when the last checkpoint was made
+	err        error     // from failed automatic checkpoints
+}
+
+// NewStreamDeduper returns a new StreamDeduper which holds at most 2×limit
+// elements, or which is unbounded if limit is <= 0. The type must be
+// specified since it can't be inferred.
+func NewStreamDeduper[E comparable](limit int) *StreamDeduper[E] {
+	return &StreamDeduper[E]{current: New[E](), previous: New[E](),
+		limit: limit}
+}
+
+// FirstSeen returns true and remembers element if it hasn't been seen (or
+// has been forgotten); otherwise returns false.
+func (me *StreamDeduper[E]) FirstSeen(element E) bool {
+	if me.current.Contains(element) {
+		return false
+	}
+	seen := me.previous.Contains(element)
+	if me.limit > 0 && me.current.Len() >= me.limit {
+		me.previous, me.current = me.current, me.previous
+		me.current.Clear()
+	}
+	me.current.set[element] = struct{}{} // refresh any previous sighting
+	if !seen {
+		me.maybeCheckpoint()
+	}
+	return !seen
+}
+
+// Filter returns an iterator over the given sequence's elements that skips
+// any that have been seen before, e.g.,
+// for line := range deduper.Filter(lines) ...
+// Sequences filtered by the same StreamDeduper share its memory, so
+// merging several sources through one StreamDeduper dedups across them.
+func (me *StreamDeduper[E]) Filter(seq iter.Seq[E]) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for element := range seq {
+			if me.FirstSeen(element) && !yield(element) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of elements currently remembered.
+func (me *StreamDeduper[E]) Len() int {
+	return me.current.Len() + me.previous.Len()
+}
+
+// Clear forgets every element seen.
+func (me *StreamDeduper[E]) Clear() {
+	me.current.Clear()
+	me.previous.Clear()
+}
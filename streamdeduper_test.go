@@ -0,0 +1,45 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStreamDeduper(t *testing.T) {
+	d := NewStreamDeduper[string](0)
+	a := slices.Values([]string{"x", "y", "x", "z"})
+	b := slices.Values([]string{"z", "w", "y"})
+	got := slices.Collect(d.Filter(a))
+	got = slices.AppendSeq(got, d.Filter(b))
+	if !slices.Equal(got, []string{"x", "y", "z", "w"}) {
+		t.Errorf("expected [x y z w], got %v", got)
+	}
+	if d.Len() != 4 {
+		t.Errorf("expected 4, got %d", d.Len())
+	}
+	d.Clear()
+	if !d.FirstSeen("x") {
+		t.Error("expected x to be forgotten")
+	}
+}
+
+func TestStreamDeduperBounded(t *testing.T) {
+	d := NewStreamDeduper[int](2)
+	got := slices.Collect(d.Filter(slices.Values(
+		[]int{1, 2, 3, 1, 4, 5, 6, 1, 3})))
+	// The first repeat of 1 is soon enough to be suppressed, but by the
+	// time 1 and 3 recur at the end they have been forgotten.
+	if !slices.Equal(got, []int{1, 2, 3, 4, 5, 6, 1, 3}) {
+		t.Errorf("unexpected %v", got)
+	}
+	if d.Len() > 4 {
+		t.Errorf("expected at most 4 remembered, got %d", d.Len())
+	}
+	for _, x := range []int{7, 8, 9} {
+		d.FirstSeen(x)
+	}
+	if d.Len() > 4 {
+		t.Errorf("expected at most 4 remembered, got %d", d.Len())
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+//go:build setstress
+
+package set
+
+// This is a soak test for the concurrent set types, e.g.,
+//
+//	go test -tags setstress -race -run Stress -stress.duration=5m .
+//
+// Each worker mixes operations on elements of its own (which only it
+// changes, so it knows exactly what Contains must report) with operations
+// on elements shared by all the workers. For shared elements AddIfAbsent
+// acts as a lock: only one worker at a time may succeed, and only that
+// worker deletes the element again, so its CompareAndDelete must succeed.
+// Any other outcome means a single-element operation wasn't linearizable.
+
+import (
+	"flag"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	stressDuration = flag.Duration("stress.duration", 2*time.Second,
+		"how long each stress test runs")
+	stressWorkers = flag.Int("stress.workers", 8,
+		"how many goroutines each stress test uses")
+)
+
+const (
+	stressShared  = 64   // shared elements are 0..stressShared-1
+	stressPrivate = 1024 // elements per worker
+)
+
+func TestStressSyncSet(t *testing.T) {
+	stress(t, NewSync[int]())
+}
+
+func TestStressReadMostlySet(t *testing.T) {
+	stress(t, NewReadMostly[int]())
+}
+
+func stress(t *testing.T, s ConcurrentSet[int]) {
+	var holders [stressShared]atomic.Int32
+	var failed atomic.Bool
+	fail := func(format string, args ...any) {
+		failed.Store(true)
+		t.Errorf(format, args...)
+	}
+	deadline := time.Now().Add(*stressDuration)
+	var wg sync.WaitGroup
+	for worker := range *stressWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(uint64(worker), 1))
+			base := stressShared + worker*stressPrivate
+			mine := make(map[int]bool, stressPrivate)
+			for time.Now().Before(deadline) && !failed.Load() {
+				for range 100 {
+					stressStep(s, rng, base, mine, &holders, fail)
+				}
+			}
+			for element, present := range mine {
+				if s.Contains(element) != present {
+					fail("private %d: expected present=%t at end",
+						element, present)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range holders {
+		if n := holders[i].Load(); n != 0 {
+			t.Errorf("shared %d: %d holders at end", i, n)
+		}
+		if s.Contains(i) {
+			t.Errorf("shared %d: still present at end", i)
+		}
+	}
+}
+
+func stressStep(s ConcurrentSet[int], rng *rand.Rand, base int,
+	mine map[int]bool, holders *[stressShared]atomic.Int32,
+	fail func(string, ...any)) {
+	switch rng.IntN(10) {
+	case 0, 1: // Take and release a shared element
+		element := rng.IntN(stressShared)
+		if !s.AddIfAbsent(element) {
+			return
+		}
+		if n := holders[element].Add(1); n != 1 {
+			fail("shared %d: AddIfAbsent succeeded with %d holders",
+				element, n-1)
+		}
+		if !s.Contains(element) {
+			fail("shared %d: missing while held", element)
+		}
+		holders[element].Add(-1)
+		if !s.CompareAndDelete(element) {
+			fail("shared %d: holder's CompareAndDelete failed", element)
+		}
+	case 2, 3: // Add or delete a private element
+		element := base + rng.IntN(stressPrivate)
+		if mine[element] {
+			s.Delete(element)
+		} else {
+			s.Add(element)
+		}
+		mine[element] = !mine[element]
+	case 4, 5, 6: // Check a private element
+		element := base + rng.IntN(stressPrivate)
+		if s.Contains(element) != mine[element] {
+			fail("private %d: expected present=%t", element,
+				mine[element])
+		}
+	case 7: // Conditional operations on a private element
+		element := base + rng.IntN(stressPrivate)
+		if mine[element] {
+			if !s.CompareAndDelete(element) {
+				fail("private %d: CompareAndDelete failed", element)
+			}
+		} else if !s.AddIfAbsent(element) {
+			fail("private %d: AddIfAbsent failed", element)
+		}
+		mine[element] = !mine[element]
+	case 8: // Whole-set reads
+		n := s.Len()
+		if n < 0 || n > stressShared+*stressWorkers*stressPrivate {
+			fail("Len %d out of range", n)
+		}
+		snapshot := s.Snapshot()
+		for element := range snapshot.All() {
+			if element < 0 {
+				fail("Snapshot has impossible element %d", element)
+			}
+		}
+	case 9: // Iteration and algebra
+		for element := range s.All() {
+			if element >= base && element < base+stressPrivate &&
+				!mine[element] {
+				fail("private %d: iterated but deleted", element)
+			}
+		}
+		_ = s.Intersection(New(base, base+1))
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// Sync reconciles this Set with a peer's Set over session (e.g., a network
+// connection) so that afterwards both hold the union of their elements.
+// Both processes must call Sync at the same time on either end of the
+// session with compatible codecs. The peers first exchange their
+// [Set.Digest]s and stop if they agree; otherwise they exchange digests of
+// buckets of elements (grouped by [StableHash]) and then only the elements
+// in the buckets that differ, so similar Sets reconcile with little
+// traffic. If codec is nil, the one returned by [LookupCodec] is used, so
+// custom element types can take part by calling [RegisterCodec]. Returns
+// the number of elements added to this Set. The elements must be stable
+// hashable. If an error is returned the session is in an unknown state
+// and should be closed.
+func (me *Set[E]) Sync(session io.ReadWriter, codec Codec[E]) (int, error) {
+	if codec == nil {
+		var ok bool
+		if codec, ok = LookupCodec[E](); !ok {
+			return 0, fmt.Errorf("cannot sync: no codec for %T",
+				*new(E))
+		}
+	}
+	digest, err := me.Digest()
+	if err != nil {
+		return 0, err
+	}
+	var peer [16]byte
+	local := binary.BigEndian.AppendUint64(nil, uint64(len(me.set)))
+	local = binary.BigEndian.AppendUint64(local, digest)
+	if err := exchange(session, local, func(r io.Reader) error {
+		_, err := io.ReadFull(r, peer[:])
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	if binary.BigEndian.Uint64(peer[8:]) == digest {
+		return 0, nil
+	}
+	// Clamped so that the conversion can't wrap on 32-bit platforms; the
+	// bucket count stops growing long before math.MaxInt anyway.
+	peerLen := min(binary.BigEndian.Uint64(peer[:8]), math.MaxInt)
+	n := syncBuckets(max(len(me.set), int(peerLen)))
+	buckets := make([]Set[E], n)
+	digests := make([]uint64, n)
+	for element := range me.set {
+		h, _ := StableHash(element) // Digest succeeded so this can't fail
+		i := h % uint64(n)
+		if buckets[i].set == nil {
+			buckets[i] = New[E]()
+		}
+		buckets[i].set[element] = struct{}{}
+		digests[i] += mix64(h)
+	}
+	local = local[:0]
+	for _, d := range digests {
+		local = binary.BigEndian.AppendUint64(local, d)
+	}
+	peerDigests := make([]byte, 8*n)
+	if err := exchange(session, local, func(r io.Reader) error {
+		_, err := io.ReadFull(r, peerDigests)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	missing := New[E]()
+	for i, d := range digests {
+		if binary.BigEndian.Uint64(peerDigests[i*8:]) != d {
+			missing.Unite(buckets[i])
+		}
+	}
+	var out bytes.Buffer
+	out.Write(make([]byte, 8)) // placeholder for the length
+	if err := missing.EncodeTo(&out, codec); err != nil {
+		return 0, err
+	}
+	binary.BigEndian.PutUint64(out.Bytes(), uint64(out.Len()-8))
+	var received Set[E]
+	if err := exchange(session, out.Bytes(), func(r io.Reader) error {
+		var size [8]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return err
+		}
+		var raw bytes.Buffer
+		// Don't trust size for allocating: the data may be corrupt.
+		if _, err := io.CopyN(&raw, r, int64(min(binary.BigEndian.Uint64(
+			size[:]), 1<<62))); err != nil {
+			return unexpectedEOF(err)
+		}
+		received, err = DecodeFrom(&raw, codec)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	added := 0
+	for element := range received.set {
+		if _, ok := me.set[element]; !ok {
+			me.set[element] = struct{}{}
+			added++
+		}
+	}
+	return added, nil
+}
+
+// syncBuckets returns the number of buckets to use for reconciling sets
+// of up to n elements: a power of two giving about 8 elements per bucket,
+// between 1 and 4096.
+func syncBuckets(n int) int {
+	if n <= 8 {
+		return 1
+	}
+	return 1 << min(12, bits.Len(uint(n-1)/8))
+}
+
+// exchange concurrently writes out to and reads from rw (using read), so
+// that two peers exchanging messages over an unbuffered stream don't
+// deadlock.
+func exchange(rw io.ReadWriter, out []byte,
+	read func(io.Reader) error) error {
+	errc := make(chan error, 1)
+	go func() {
+		_, err := rw.Write(out)
+		errc <- err
+	}()
+	if err := read(rw); err != nil {
+		return err // the writer may be blocked until rw is closed
+	}
+	if err := <-errc; err != nil {
+		return fmt.Errorf("cannot send: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"math"
+	"net"
+	"testing"
+)
+
+func syncPair[E comparable](t *testing.T, a, b *Set[E],
+	codec Codec[E]) (int, int) {
+	t.Helper()
+	x, y := net.Pipe()
+	defer x.Close()
+	defer y.Close()
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		n, err := b.Sync(y, codec)
+		done <- result{n, err}
+	}()
+	na, err := a.Sync(x, codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb := <-done
+	if rb.err != nil {
+		t.Fatal(rb.err)
+	}
+	return na, rb.n
+}
+
+func TestSync(t *testing.T) {
+	a, b := New[int](), New[int]()
+	for i := range 1000 {
+		a.Add(i)
+		b.Add(i)
+	}
+	a.Add(-1, -2)
+	b.Add(5000)
+	na, nb := syncPair(t, &a, &b, IntCodec[int]())
+	if na != 1 || nb != 2 {
+		t.Errorf("expected 1 and 2 added, got %d %d", na, nb)
+	}
+	if !a.Equal(b) || a.Len() != 1003 {
+		t.Errorf("expected equal sets of 1003, got %d %d", a.Len(), b.Len())
+	}
+	na, nb = syncPair(t, &a, &b, IntCodec[int]())
+	if na != 0 || nb != 0 {
+		t.Errorf("expected nothing added, got %d %d", na, nb)
+	}
+}
+
+func TestSyncEmpty(t *testing.T) {
+	a, b := New[string](), New("x", "y")
+	syncPair(t, &a, &b, StringCodec[string]())
+	check(sortedStr(a), a.Len(), "{\"x\" \"y\"}", 2, t)
+}
+
+func TestSyncBuckets(t *testing.T) {
+	for _, c := range [][2]int{{0, 1}, {8, 1}, {9, 2}, {1000, 128},
+		{1 << 30, 4096}, {math.MaxInt, 4096}} {
+		if n := syncBuckets(c[0]); n != c[1] {
+			t.Errorf("%d: expected %d, got %d", c[0], c[1], n)
+		}
+	}
+}
+
+func TestSyncLookupCodec(t *testing.T) {
+	a, b := New[label]("red"), New[label]("green")
+	syncPair(t, &a, &b, nil)
+	check(sortedStr(b), b.Len(), "{green red}", 2, t)
+	type opaque struct{ x int }
+	c := New(opaque{1})
+	if _, err := c.Sync(nil, nil); err == nil {
+		t.Error("expected error for element type without a codec")
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// NewFromSyncMapKeys returns a new Set containing the keys of the given
+// sync.Map. Keys that are not of type E are skipped. Since the sync.Map may
+// be concurrently modified the result reflects no particular moment; see
+// sync.Map.Range.
+func NewFromSyncMapKeys[E comparable](m *sync.Map) Set[E] {
+	set := New[E]()
+	m.Range(func(key, _ any) bool {
+		if element, ok := key.(E); ok {
+			set.set[element] = struct{}{}
+		}
+		return true
+	})
+	return set
+}
+
+// SyncMapSnapshot holds an immutable snapshot of the keys of a sync.Map
+// that is used for concurrent membership updates. This suits query-heavy
+// workloads: readers call [SyncMapSnapshot.Load] and query the returned
+// FrozenSet without any locking, while one goroutine periodically calls
+// [SyncMapSnapshot.Refresh], e.g.,
+//
+//	snap := set.NewSyncMapSnapshot[string](&members)
+//	go func() {
+//		for range time.Tick(time.Minute) {
+//			snap.Refresh()
+//		}
+//	}()
+//	...
+//	if snap.Load().Contains(id) { ... }
+type SyncMapSnapshot[E comparable] struct {
+	m    *sync.Map
+	snap atomic.Pointer[FrozenSet[E]]
+}
+
+// NewSyncMapSnapshot returns a new SyncMapSnapshot of the given sync.Map's
+// keys, already refreshed.
+func NewSyncMapSnapshot[E comparable](m *sync.Map) *SyncMapSnapshot[E] {
+	snap := &SyncMapSnapshot[E]{m: m}
+	snap.Refresh()
+	return snap
+}
+
+// Refresh replaces the current snapshot with a new one built from the
+// sync.Map's keys. Readers holding the previous snapshot are unaffected.
+func (me *SyncMapSnapshot[E]) Refresh() {
+	set := NewFromSyncMapKeys[E](me.m)
+	me.snap.Store(&FrozenSet[E]{set.set})
+}
+
+// Load returns the current snapshot. It is safe to call concurrently
+// with [SyncMapSnapshot.Refresh].
+func (me *SyncMapSnapshot[E]) Load() *FrozenSet[E] { return me.snap.Load() }
@@ -0,0 +1,44 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewFromSyncMapKeys(t *testing.T) {
+	var m sync.Map
+	m.Store(1, true)
+	m.Store(2, "x")
+	m.Store("three", 3)
+	s := NewFromSyncMapKeys[int](&m)
+	check(sortedStr(s), s.Len(), "{1 2}", 2, t)
+}
+
+func TestSyncMapSnapshot(t *testing.T) {
+	var m sync.Map
+	m.Store("a", struct{}{})
+	snap := NewSyncMapSnapshot[string](&m)
+	old := snap.Load()
+	if !old.Contains("a") || old.Len() != 1 {
+		t.Errorf("expected {a}, got %s", old.String())
+	}
+	var wg sync.WaitGroup
+	for _, key := range []string{"b", "c", "d"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Store(key, struct{}{})
+			_ = snap.Load().Contains(key)
+		}()
+	}
+	wg.Wait()
+	snap.Refresh()
+	now := snap.Load()
+	if now.Len() != 4 || !now.Contains("d") {
+		t.Errorf("expected 4 keys, got %s", now.String())
+	}
+	if old.Len() != 1 {
+		t.Errorf("expected old snapshot unchanged, got %s", old.String())
+	}
+}
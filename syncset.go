@@ -0,0 +1,269 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncSet is a Set that is safe for concurrent use by multiple goroutines.
+// Its methods mirror those of [Set]. The algebra methods take a plain Set
+// for the other operand; to combine two SyncSets, pass the other's
+// [SyncSet.Clone]. Reads never lock: the SyncSet holds an atomically
+// swapped pointer to a map that is never modified once stored, so
+// Contains, Len, iteration (see [SyncSet.All]), [SyncSet.Snapshot], and
+// the other read methods load the current map and always see a
+// consistent point-in-time view. Writes are serialized by a mutex; each
+// that changes the SyncSet copies the map, changes the copy, and swaps it
+// in (copy-on-write), so it is O(n). A SyncSet therefore suits workloads
+// of mostly reads; for large sets with steady writes a [ReadMostlySet]
+// (whose writes are O(1), but whose iteration isn't a point-in-time view)
+// may be better. BenchmarkConcurrentContains in the benchmarks package
+// compares them with an RWMutex-guarded Set. A SyncSet must not be copied
+// after first use.
+type SyncSet[E comparable] struct {
+	mutex   sync.Mutex                     // serializes writers
+	current atomic.Pointer[map[E]struct{}] // nil means empty
+}
+
+// NewSync returns a new SyncSet containing the given elements (if any).
+// If no elements are given, the type must be specified since it can't be
+// inferred.
+func NewSync[E comparable](elements ...E) *SyncSet[E] {
+	set := &SyncSet[E]{}
+	elementSet := New(elements...)
+	set.current.Store(&elementSet.set)
+	return set
+}
+
+// Add adds the given element(s) to the SyncSet.
+func (me *SyncSet[E]) Add(elements ...E) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	if set := me.load(); !slices.ContainsFunc(elements, func(element E) bool {
+		return !set.Contains(element)
+	}) {
+		return // all present so no need to copy
+	}
+	me.swap(func(set *Set[E]) { set.Add(elements...) })
+}
+
+// AddIfAbsent adds element to the SyncSet and returns true if it wasn't
+// already present; otherwise returns false. The check and the add are one
+// atomic step, so of several goroutines adding the same element exactly
+// one gets true, e.g., for dedup pipelines.
+func (me *SyncSet[E]) AddIfAbsent(element E) bool {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	if set := me.load(); set.Contains(element) {
+		return false
+	}
+	me.swap(func(set *Set[E]) { set.Add(element) })
+	return true
+}
+
+// EnsureWith adds element to the SyncSet and calls onFirst and returns
+// true if it wasn't already present; otherwise returns false. Of several
+// goroutines ensuring the same element exactly one calls onFirst, e.g.,
+// so that only the first worker processes an ID. onFirst is called
+// without the lock held (so it may use the SyncSet), and the others don't
+// wait for it to finish.
+func (me *SyncSet[E]) EnsureWith(element E, onFirst func()) bool {
+	if me.Contains(element) || !me.AddIfAbsent(element) { // double-check
+		return false
+	}
+	onFirst()
+	return true
+}
+
+// CompareAndDelete deletes element from the SyncSet and returns true if it
+// was present; otherwise returns false. The check and the delete are one
+// atomic step, so of several goroutines deleting the same element exactly
+// one gets true.
+func (me *SyncSet[E]) CompareAndDelete(element E) bool {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	if set := me.load(); !set.Contains(element) {
+		return false
+	}
+	me.swap(func(set *Set[E]) { set.Delete(element) })
+	return true
+}
+
+// Update calls update with a copy of the SyncSet's elements while holding
+// the write lock, and then swaps the copy in, so that multi-step changes
+// (e.g., "delete x, then add y if absent") are atomic, and readers see
+// either none or all of them. The update function must not retain the Set
+// or call the SyncSet's write methods (which would deadlock).
+func (me *SyncSet[E]) Update(update func(set *Set[E])) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	me.swap(update)
+}
+
+// Delete deletes the given element(s) from the SyncSet.
+func (me *SyncSet[E]) Delete(elements ...E) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	if set := me.load(); !slices.ContainsFunc(elements, set.Contains) {
+		return // none present so no need to copy
+	}
+	me.swap(func(set *Set[E]) { set.Delete(elements...) })
+}
+
+// Clear deletes all the elements in the SyncSet.
+func (me *SyncSet[E]) Clear() {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	me.current.Store(nil)
+}
+
+// Len returns the number of elements in the SyncSet.
+func (me *SyncSet[E]) Len() int {
+	set := me.load()
+	return set.Len()
+}
+
+// IsEmpty returns true if there are no elements in the SyncSet; otherwise
+// returns false.
+func (me *SyncSet[E]) IsEmpty() bool {
+	set := me.load()
+	return set.IsEmpty()
+}
+
+// Contains returns true if element is in the SyncSet; otherwise returns
+// false.
+func (me *SyncSet[E]) Contains(element E) bool {
+	set := me.load()
+	return set.Contains(element)
+}
+
+// Difference returns a new Set that contains the elements which are in
+// this SyncSet that are not in the other Set.
+func (me *SyncSet[E]) Difference(other Set[E]) Set[E] {
+	set := me.load()
+	return set.Difference(other)
+}
+
+// SymmetricDifference returns a new Set that contains the elements which
+// are in this SyncSet or the other Set—but not in both.
+func (me *SyncSet[E]) SymmetricDifference(other Set[E]) Set[E] {
+	set := me.load()
+	return set.SymmetricDifference(other)
+}
+
+// Intersection returns a new Set that contains the elements this SyncSet
+// has in common with the other Set.
+func (me *SyncSet[E]) Intersection(other Set[E]) Set[E] {
+	set := me.load()
+	return set.Intersection(other)
+}
+
+// Union returns a new Set that contains the elements from this SyncSet
+// and from the other Set.
+func (me *SyncSet[E]) Union(other Set[E]) Set[E] {
+	set := me.load()
+	return set.Union(other)
+}
+
+// Unite adds all the elements from other that aren't already in this
+// SyncSet to this SyncSet.
+func (me *SyncSet[E]) Unite(other Set[E]) {
+	me.mutex.Lock()
+	defer me.mutex.Unlock()
+	if set := me.load(); other.IsSubsetOf(set) {
+		return // all present so no need to copy
+	}
+	me.swap(func(set *Set[E]) { set.Unite(other) })
+}
+
+// Clone returns a copy of this SyncSet's elements as a plain Set.
+func (me *SyncSet[E]) Clone() Set[E] {
+	set := me.load()
+	return set.Clone()
+}
+
+// Equal returns true if this SyncSet has the same elements as the other
+// Set; otherwise returns false.
+func (me *SyncSet[E]) Equal(other Set[E]) bool {
+	set := me.load()
+	return set.Equal(other)
+}
+
+// IsDisjoint returns true if this SyncSet has no elements in common with
+// the other Set; otherwise returns false.
+func (me *SyncSet[E]) IsDisjoint(other Set[E]) bool {
+	set := me.load()
+	return set.IsDisjoint(other)
+}
+
+// IsSubsetOf returns true if every member of this SyncSet is in the other
+// Set; otherwise returns false.
+func (me *SyncSet[E]) IsSubsetOf(other Set[E]) bool {
+	set := me.load()
+	return set.IsSubsetOf(other)
+}
+
+// IsSupersetOf returns true if every member of the other Set is in this
+// SyncSet; otherwise returns false.
+func (me *SyncSet[E]) IsSupersetOf(other Set[E]) bool {
+	return other.IsSubsetOf(me.load())
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+// The loop sees the elements as they were when it started, however other
+// goroutines change the SyncSet meanwhile; the loop body may itself
+// change the SyncSet.
+func (me *SyncSet[E]) All() iter.Seq[E] {
+	return func(yield func(E) bool) {
+		set := me.load()
+		for element := range set.set {
+			if !yield(element) {
+				return
+			}
+		}
+	}
+}
+
+// Snapshot returns a FrozenSet of the SyncSet's elements at the time of
+// the call. This is O(1): the FrozenSet shares the SyncSet's current map,
+// which is never modified.
+func (me *SyncSet[E]) Snapshot() FrozenSet[E] {
+	if current := me.current.Load(); current != nil {
+		return FrozenSet[E]{*current}
+	}
+	return NewFrozen[E]()
+}
+
+// ToSlice returns this SyncSet's elements as an unsorted slice.
+func (me *SyncSet[E]) ToSlice() []E {
+	set := me.load()
+	return set.ToSlice()
+}
+
+// String returns a human readable string representation of the SyncSet.
+func (me *SyncSet[E]) String() string {
+	set := me.load()
+	return set.String()
+}
+
+// load returns the SyncSet's current elements, which must not be
+// modified; the zero SyncSet's are empty.
+func (me *SyncSet[E]) load() Set[E] {
+	if current := me.current.Load(); current != nil {
+		return Set[E]{set: *current}
+	}
+	return Set[E]{}
+}
+
+// swap copies the SyncSet's current elements, calls change with the copy,
+// and stores the result for readers to load. The mutex must be held.
+func (me *SyncSet[E]) swap(change func(set *Set[E])) {
+	current := me.load()
+	set := current.Clone()
+	change(&set)
+	me.current.Store(&set.set)
+}
@@ -0,0 +1,280 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSyncSet(t *testing.T) {
+	s := NewSync(1, 2, 3)
+	other := New(3, 4)
+	u := s.Union(other)
+	i := s.Intersection(other)
+	d := s.Difference(other)
+	x := s.SymmetricDifference(other)
+	check(sortedStr(u), u.Len(), "{1 2 3 4}", 4, t)
+	check(sortedStr(i), i.Len(), "{3}", 1, t)
+	check(sortedStr(d), d.Len(), "{1 2}", 2, t)
+	check(sortedStr(x), x.Len(), "{1 2 4}", 3, t)
+	if s.IsDisjoint(other) || !s.IsSupersetOf(New(1, 2)) ||
+		s.IsSubsetOf(other) || !s.Equal(New(3, 2, 1)) {
+		t.Error("unexpected comparisons")
+	}
+	s.Unite(other)
+	s.Delete(1)
+	c := s.Clone()
+	check(sortedStr(c), s.Len(), "{2 3 4}", 3, t)
+	n := 0
+	for range s.All() {
+		n++
+	}
+	if n != 3 || len(s.ToSlice()) != 3 {
+		t.Errorf("expected 3, got %d", n)
+	}
+	s.Clear()
+	if !s.IsEmpty() || s.Contains(2) || s.String() != "{}" {
+		t.Error("unexpected nonempty")
+	}
+	var zero SyncSet[string]
+	zero.Add("x")
+	if !zero.Contains("x") {
+		t.Error("expected zero SyncSet to be usable")
+	}
+}
+
+func TestSyncSetConcurrent(t *testing.T) {
+	s := NewSync[int]()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				s.Add(g*1000 + i)
+				s.Contains(i)
+				if i%10 == 0 {
+					s.Delete(g*1000 + i)
+				}
+				if i%100 == 0 {
+					for range s.All() {
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 8*900 {
+		t.Errorf("expected %d, got %d", 8*900, s.Len())
+	}
+}
+
+func TestSyncSetAddIfAbsent(t *testing.T) {
+	var s SyncSet[int]
+	var added, deleted [8]int
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 500 {
+				if s.AddIfAbsent(i) {
+					added[g]++
+				}
+			}
+			for i := range 500 {
+				if s.CompareAndDelete(i) {
+					deleted[g]++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	totalAdded, totalDeleted := 0, 0
+	for g := range 8 {
+		totalAdded += added[g]
+		totalDeleted += deleted[g]
+	}
+	if totalAdded < 500 || totalAdded != totalDeleted || !s.IsEmpty() {
+		t.Errorf("expected each add matched by one delete, got %d %d %d",
+			totalAdded, totalDeleted, s.Len())
+	}
+	if !s.AddIfAbsent(1) || s.AddIfAbsent(1) || !s.CompareAndDelete(1) ||
+		s.CompareAndDelete(1) {
+		t.Error("unexpected results")
+	}
+}
+
+func TestSyncSetEnsureWith(t *testing.T) {
+	testEnsureWith(t, NewSync[int]())
+}
+
+// testEnsureWith checks that of many goroutines ensuring the same elements
+// each element's onFirst is called exactly once.
+func testEnsureWith(t *testing.T, s ConcurrentSet[int]) {
+	var calls [100]atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range calls {
+				s.EnsureWith(i, func() { calls[i].Add(1) })
+			}
+		}()
+	}
+	wg.Wait()
+	for i := range calls {
+		if n := calls[i].Load(); n != 1 {
+			t.Errorf("%d: expected 1 call, got %d", i, n)
+		}
+	}
+	if s.Len() != len(calls) || s.EnsureWith(0, func() {
+		t.Error("unexpected call for a present element")
+	}) {
+		t.Errorf("unexpected result for %d elements", s.Len())
+	}
+}
+
+func TestSyncSetSnapshotIteration(t *testing.T) {
+	s := NewSync(1, 2, 3)
+	n := 0
+	for x := range s.All() {
+		s.Add(x + 100) // allowed; the loop doesn't see these
+		s.Delete(x)
+		n++
+	}
+	if n != 3 {
+		t.Errorf("expected 3 iterations, got %d", n)
+	}
+	c := s.Clone()
+	check(sortedStr(c), s.Len(), "{101 102 103}", 3, t)
+	snap := s.Snapshot()
+	s.Add(7)
+	s.Clear()
+	check(sortedStr(snap.Thaw()), snap.Len(), "{101 102 103}", 3, t)
+	s.Add(8)
+	if snap.Contains(8) || !s.Contains(8) || s.Len() != 1 {
+		t.Error("expected snapshot to be unaffected")
+	}
+	var zero SyncSet[int]
+	for range zero.All() {
+		t.Error("unexpected element")
+	}
+	if z := zero.Snapshot(); !z.IsEmpty() {
+		t.Error("expected empty snapshot")
+	}
+}
+
+func TestSyncSetIterateWhileWriting(t *testing.T) {
+	s := NewSync[int]()
+	for i := range 100 {
+		s.Add(i)
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := range 2000 {
+			s.Add(100 + i)
+			s.Delete(i % 100)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for range 50 {
+			snap := s.Snapshot()
+			n := 0
+			for range s.All() {
+				n++
+			}
+			if n == 0 || snap.Len() == 0 {
+				t.Error("unexpected empty view")
+			}
+		}
+	}()
+	wg.Wait()
+}
+
+func TestSyncSetUpdate(t *testing.T) {
+	s := NewSync("x", "z")
+	snap := s.Snapshot()
+	s.Update(func(set *Set[string]) {
+		set.Delete("x")
+		if !set.Contains("y") {
+			set.Add("y")
+		}
+	})
+	c := s.Clone()
+	check(sortedStr(c), s.Len(), "{\"y\" \"z\"}", 2, t)
+	check(sortedStr(snap.Thaw()), snap.Len(), "{\"x\" \"z\"}", 2, t)
+	var counter SyncSet[int]
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				counter.Update(func(set *Set[int]) {
+					set.Add(set.Len()) // read-modify-write
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if counter.Len() != 800 {
+		t.Errorf("expected 800, got %d", counter.Len())
+	}
+}
+
+func TestSyncSetClearAfterSnapshot(t *testing.T) {
+	s := NewSync(1)
+	snap := s.Snapshot()
+	s.Clear()
+	u := s.Union(New(2))
+	u.Add(3)
+	check(sortedStr(u), u.Len(), "{2 3}", 2, t)
+	c := s.Clone()
+	c.Add(4)
+	check(sortedStr(c), c.Len(), "{4}", 1, t)
+	if !snap.Contains(1) || snap.Len() != 1 {
+		t.Error("expected snapshot to be unaffected")
+	}
+	var zero SyncSet[int]
+	u = zero.Union(New(2))
+	c = zero.Clone()
+	c.Add(5)
+	check(sortedStr(u), u.Len(), "{2}", 1, t)
+	check(sortedStr(c), c.Len(), "{5}", 1, t)
+}
+
+func TestSyncSetUpdateIsAtomicToReaders(t *testing.T) {
+	s := NewSync(-1, 0)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := range 1000 {
+			s.Update(func(set *Set[int]) { // swap i for i+1
+				set.Delete(i)
+				set.Add(i + 1)
+			})
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			wg.Wait()
+			check(sortedStr(s.Clone()), s.Len(), "{-1 1000}", 2, t)
+			return
+		default:
+			snap := s.Snapshot()
+			if snap.Len() != 2 || !snap.Contains(-1) {
+				t.Fatalf("saw a partial update: %v", snap.Thaw())
+			}
+		}
+	}
+}
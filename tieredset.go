@@ -0,0 +1,122 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import "container/list"
+
+// ColdStore is the interface a [TieredSet] uses for its cold tier, e.g., a
+// disk- or network-backed membership store.
+type ColdStore[E comparable] interface {
+	Contains(element E) (bool, error)
+	Add(element E) error
+	Delete(element E) error
+}
+
+// TieredSet is a two-level set with a small in-memory hot tier in front of
+// a (typically much larger) cold tier. The cold tier is the source of
+// truth: Add and Delete write through to it, and Contains only consults it
+// on a hot miss. The hot tier holds at most hotSize elements and evicts
+// the least recently used one when full.
+type TieredSet[E comparable] struct {
+	hot     map[E]*list.Element
+	lru     *list.List
+	hotSize int
+	cold    ColdStore[E]
+	promote func(E) bool
+}
+
+// NewTiered returns a new TieredSet whose hot tier holds at most hotSize
+// elements and which uses cold as its cold tier. The promote function is
+// the promotion policy: it is called for elements found in the cold tier
+// and should return true if they are to be copied into the hot tier. If
+// promote is nil every cold hit is promoted.
+func NewTiered[E comparable](hotSize int, cold ColdStore[E],
+	promote func(E) bool) *TieredSet[E] {
+	return &TieredSet[E]{hot: make(map[E]*list.Element, hotSize),
+		lru: list.New(), hotSize: max(1, hotSize), cold: cold,
+		promote: promote}
+}
+
+// Add adds the given element(s) to the cold tier and then to the hot tier.
+// If the cold tier fails, Add stops and returns the error.
+func (me *TieredSet[E]) Add(elements ...E) error {
+	for _, element := range elements {
+		if err := me.cold.Add(element); err != nil {
+			return err
+		}
+		me.touch(element)
+	}
+	if debug {
+		me.checkInvariants()
+	}
+	return nil
+}
+
+// Delete deletes the given element(s) from both tiers.
+// If the cold tier fails, Delete stops and returns the error.
+func (me *TieredSet[E]) Delete(elements ...E) error {
+	for _, element := range elements {
+		if item, ok := me.hot[element]; ok {
+			me.lru.Remove(item)
+			delete(me.hot, element)
+		}
+		if err := me.cold.Delete(element); err != nil {
+			return err
+		}
+	}
+	if debug {
+		me.checkInvariants()
+	}
+	return nil
+}
+
+// Contains returns true if element is in the hot tier, or if it isn't but
+// is in the cold tier; otherwise returns false. A cold hit is promoted into
+// the hot tier if the promotion policy allows.
+func (me *TieredSet[E]) Contains(element E) (bool, error) {
+	if item, ok := me.hot[element]; ok {
+		me.lru.MoveToFront(item)
+		return true, nil
+	}
+	ok, err := me.cold.Contains(element)
+	if err != nil || !ok {
+		return false, err
+	}
+	if me.promote == nil || me.promote(element) {
+		me.touch(element)
+		if debug {
+			me.checkInvariants()
+		}
+	}
+	return true, nil
+}
+
+// IsHot returns true if element is currently in the hot tier; otherwise
+// returns false. It never consults the cold tier.
+func (me *TieredSet[E]) IsHot(element E) bool {
+	_, ok := me.hot[element]
+	return ok
+}
+
+// HotLen returns the number of elements in the hot tier.
+func (me *TieredSet[E]) HotLen() int { return len(me.hot) }
+
+// ClearHot deletes all the elements in the hot tier (leaving the cold tier
+// untouched).
+func (me *TieredSet[E]) ClearHot() {
+	clear(me.hot)
+	me.lru.Init()
+}
+
+func (me *TieredSet[E]) touch(element E) {
+	if item, ok := me.hot[element]; ok {
+		me.lru.MoveToFront(item)
+		return
+	}
+	if len(me.hot) >= me.hotSize {
+		oldest := me.lru.Back()
+		me.lru.Remove(oldest)
+		delete(me.hot, oldest.Value.(E))
+	}
+	me.hot[element] = me.lru.PushFront(element)
+}
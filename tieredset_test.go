@@ -0,0 +1,95 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeCold struct {
+	set  Set[int]
+	hits int
+	fail bool
+}
+
+func (me *fakeCold) Contains(element int) (bool, error) {
+	if me.fail {
+		return false, errors.New("cold failure")
+	}
+	me.hits++
+	return me.set.Contains(element), nil
+}
+
+func (me *fakeCold) Add(element int) error {
+	if me.fail {
+		return errors.New("cold failure")
+	}
+	me.set.Add(element)
+	return nil
+}
+
+func (me *fakeCold) Delete(element int) error {
+	me.set.Delete(element)
+	return nil
+}
+
+func TestTieredSet(t *testing.T) {
+	cold := &fakeCold{set: New(100, 200, 300)}
+	s := NewTiered(2, cold, nil)
+	if err := s.Add(1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	if s.HotLen() != 2 {
+		t.Errorf("expected 2 hot elements, got %d", s.HotLen())
+	}
+	if s.IsHot(1) {
+		t.Error("expected 1 to have been evicted")
+	}
+	check(sortedStr(cold.set), cold.set.Len(), "{1 2 3 100 200 300}", 6, t)
+	if ok, err := s.Contains(3); !ok || err != nil {
+		t.Errorf("expected 3 to be contained, got %t %v", ok, err)
+	}
+	if cold.hits != 0 {
+		t.Errorf("expected hot hit, got %d cold hits", cold.hits)
+	}
+	if ok, _ := s.Contains(100); !ok {
+		t.Error("expected 100 to be contained")
+	}
+	if cold.hits != 1 || !s.IsHot(100) {
+		t.Errorf("expected 100 to be promoted after 1 cold hit, got %d",
+			cold.hits)
+	}
+	if ok, _ := s.Contains(999); ok {
+		t.Error("expected 999 not to be contained")
+	}
+	if err := s.Delete(100); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := s.Contains(100); ok || s.IsHot(100) {
+		t.Error("expected 100 to be deleted")
+	}
+	s.ClearHot()
+	if s.HotLen() != 0 {
+		t.Errorf("expected no hot elements, got %d", s.HotLen())
+	}
+	cold.fail = true
+	if _, err := s.Contains(2); err == nil {
+		t.Error("expected cold failure")
+	}
+	if err := s.Add(7); err == nil || s.IsHot(7) {
+		t.Error("expected cold failure and no hot element")
+	}
+}
+
+func TestTieredSetPromotion(t *testing.T) {
+	cold := &fakeCold{set: New(1, 2, 3, 4)}
+	s := NewTiered(10, cold, func(e int) bool { return e%2 == 0 })
+	for _, e := range []int{1, 2, 3, 4} {
+		if ok, _ := s.Contains(e); !ok {
+			t.Errorf("expected %d to be contained", e)
+		}
+	}
+	if s.HotLen() != 2 || !s.IsHot(2) || !s.IsHot(4) {
+		t.Errorf("expected only even elements to be promoted")
+	}
+}
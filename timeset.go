@@ -0,0 +1,93 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"iter"
+	"slices"
+	"time"
+)
+
+// TimeSet is a set of times each of which is normalized on insert and
+// lookup: converted to UTC, stripped of any monotonic clock reading, and
+// truncated to the TimeSet's granularity. (A plain Set[time.Time] is
+// usually wrong since times that are Equal may differ in their location
+// and monotonic clock fields and so are different map keys.)
+// The times are kept in a [SortedSet] ordered by time.Time.Compare, so
+// Add, Delete, and Contains are O(log n), and Sorted and Range don't
+// need to sort.
+type TimeSet struct {
+	set         SortedSet[time.Time]
+	granularity time.Duration
+}
+
+// NewTimeSet returns a new TimeSet with the given granularity (e.g.,
+// time.Minute, time.Hour, or 24 * time.Hour) containing the given times
+// (if any). If granularity is <= 0, times are normalized but not
+// truncated.
+func NewTimeSet(granularity time.Duration, times ...time.Time) TimeSet {
+	set := TimeSet{set: NewSortedFunc(time.Time.Compare),
+		granularity: granularity}
+	set.Add(times...)
+	return set
+}
+
+// Granularity returns the TimeSet's granularity.
+func (me *TimeSet) Granularity() time.Duration { return me.granularity }
+
+// Normalize returns the given time as this TimeSet would store it.
+func (me *TimeSet) Normalize(t time.Time) time.Time {
+	t = t.UTC().Round(0)
+	if me.granularity > 0 {
+		t = t.Truncate(me.granularity)
+	}
+	return t
+}
+
+// Add adds the normalized forms of the given time(s) to the TimeSet.
+func (me *TimeSet) Add(times ...time.Time) {
+	for _, t := range times {
+		me.set.Add(me.Normalize(t))
+	}
+}
+
+// Delete deletes the normalized forms of the given time(s) from the
+// TimeSet.
+func (me *TimeSet) Delete(times ...time.Time) {
+	for _, t := range times {
+		me.set.Delete(me.Normalize(t))
+	}
+}
+
+// Clear deletes all the times in the TimeSet.
+func (me *TimeSet) Clear() { me.set.Clear() }
+
+// Len returns the number of times in the TimeSet.
+func (me *TimeSet) Len() int { return me.set.Len() }
+
+// IsEmpty returns true if there are no times in the TimeSet; otherwise
+// returns false.
+func (me *TimeSet) IsEmpty() bool { return me.set.IsEmpty() }
+
+// Contains returns true if the normalized form of t is in the TimeSet;
+// otherwise returns false.
+func (me *TimeSet) Contains(t time.Time) bool {
+	return me.set.Contains(me.Normalize(t))
+}
+
+// All returns an iterator over the (normalized) times in chronological
+// order, e.g., for t := range aset.All() ...
+func (me *TimeSet) All() iter.Seq[time.Time] { return me.set.All() }
+
+// Sorted returns the TimeSet's (normalized) times in chronological order.
+func (me *TimeSet) Sorted() []time.Time {
+	return me.set.ToSlice()
+}
+
+// Range returns the TimeSet's (normalized) times which are in the
+// half-open range [lo, hi) in chronological order. It is O(log n + k) for
+// k matching times, since only the relevant parts of the tree are
+// visited.
+func (me *TimeSet) Range(lo, hi time.Time) []time.Time {
+	return slices.AppendSeq(make([]time.Time, 0), me.set.Range(lo, hi))
+}
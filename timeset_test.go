@@ -0,0 +1,72 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTimeSet(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		paris = time.FixedZone("CET", 3600)
+	}
+	s := NewTimeSet(time.Hour)
+	now := time.Now() // has a monotonic clock reading
+	s.Add(now, now.Add(time.Second), now.In(paris))
+	if s.Len() != 1 {
+		t.Errorf("expected 1 time, got %d", s.Len())
+	}
+	if !s.Contains(now.Truncate(time.Hour).Add(59 * time.Minute)) {
+		t.Error("expected same-hour match")
+	}
+	if s.Granularity() != time.Hour {
+		t.Errorf("expected 1h, got %v", s.Granularity())
+	}
+	s.Delete(now.In(paris))
+	if !s.IsEmpty() {
+		t.Error("unexpected nonempty")
+	}
+}
+
+func TestTimeSetRange(t *testing.T) {
+	day := 24 * time.Hour
+	base := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := NewTimeSet(day)
+	for _, n := range []int{4, 0, 2, 9, 7} {
+		s.Add(base.Add(time.Duration(n)*day + 3*time.Hour))
+	}
+	got := ""
+	for _, t := range s.Range(base.Add(day), base.Add(7*day)) {
+		got += fmt.Sprintf("%d ", t.Day())
+	}
+	if got != "3 5 " {
+		t.Errorf("expected 3 5, got %s", got)
+	}
+	east := time.FixedZone("UTC+5", 5*3600)
+	if times := s.Range(base.Add(4*day).In(east),
+		base.Add(9*day).In(east)); len(times) != 2 ||
+		times[0].Day() != 5 || times[1].Day() != 8 {
+		t.Errorf("expected 5 8 for bounds in another zone, got %v", times)
+	}
+	if times := s.Range(base.Add(7*day), base.Add(day)); times == nil ||
+		len(times) != 0 {
+		t.Errorf("expected empty range, got %v", times)
+	}
+	sorted := s.Sorted()
+	if len(sorted) != 5 || sorted[0] != base || sorted[4].Day() != 10 {
+		t.Errorf("unexpected sort order %v", sorted)
+	}
+	n := 0
+	for range s.All() {
+		n++
+	}
+	if n != 5 {
+		t.Errorf("expected 5, got %d", n)
+	}
+	s.Clear()
+	if s.Len() != 0 {
+		t.Error("unexpected nonempty")
+	}
+}
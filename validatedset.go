@@ -0,0 +1,73 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"fmt"
+	"iter"
+)
+
+// ValidatedSet is a Set whose elements must all pass a validator function,
+// so that invariants (e.g., "all emails are lowercase and non-empty") are
+// enforced when elements are added rather than wherever they are used.
+type ValidatedSet[E comparable] struct {
+	set      Set[E]
+	validate func(E) error
+}
+
+// NewValidated returns a new ValidatedSet which uses the given validator
+// and contains the given elements (if any). The validator must return nil
+// for a valid element and an error describing the problem otherwise.
+// If any of the elements is invalid, the returned ValidatedSet is empty and
+// the error is returned.
+func NewValidated[E comparable](validate func(E) error,
+	elements ...E) (ValidatedSet[E], error) {
+	set := ValidatedSet[E]{set: New[E](), validate: validate}
+	err := set.TryAdd(elements...)
+	return set, err
+}
+
+// TryAdd adds the given element(s) to the ValidatedSet if they are all
+// valid; otherwise it adds none of them and returns an error for the first
+// invalid element. The error wraps both [ErrInvalidElement] and the
+// validator's error.
+func (me *ValidatedSet[E]) TryAdd(elements ...E) error {
+	for _, element := range elements {
+		if err := me.validate(element); err != nil {
+			return fmt.Errorf("%w %v: %w", ErrInvalidElement, element, err)
+		}
+	}
+	me.set.Add(elements...)
+	return nil
+}
+
+// Delete deletes the given element(s) from the ValidatedSet.
+func (me *ValidatedSet[E]) Delete(elements ...E) {
+	me.set.Delete(elements...)
+}
+
+// Clear deletes all the elements in the ValidatedSet.
+func (me *ValidatedSet[E]) Clear() { me.set.Clear() }
+
+// Len returns the number of elements in the ValidatedSet.
+func (me *ValidatedSet[E]) Len() int { return me.set.Len() }
+
+// IsEmpty returns true if there are no elements in the ValidatedSet;
+// otherwise returns false.
+func (me *ValidatedSet[E]) IsEmpty() bool { return me.set.IsEmpty() }
+
+// Contains returns true if element is in the ValidatedSet; otherwise
+// returns false.
+func (me *ValidatedSet[E]) Contains(element E) bool {
+	return me.set.Contains(element)
+}
+
+// All returns an iterator, e.g., for element := range aset.All() ...
+func (me *ValidatedSet[E]) All() iter.Seq[E] { return me.set.All() }
+
+// ToSet returns a copy of this ValidatedSet's elements as a plain Set.
+func (me *ValidatedSet[E]) ToSet() Set[E] { return me.set.Clone() }
+
+// String returns a human readable string representation of the
+// ValidatedSet.
+func (me *ValidatedSet[E]) String() string { return me.set.String() }
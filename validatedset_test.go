@@ -0,0 +1,46 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errBadEmail = errors.New("not a lowercase email")
+
+func validEmail(email string) error {
+	if email == "" || email != strings.ToLower(email) ||
+		!strings.Contains(email, "@") {
+		return errBadEmail
+	}
+	return nil
+}
+
+func TestValidatedSet(t *testing.T) {
+	s, err := NewValidated(validEmail, "a@b.com", "c@d.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(sortedStr(s.ToSet()), s.Len(), "{\"a@b.com\" \"c@d.org\"}", 2, t)
+	err = s.TryAdd("e@f.net", "Bad@X.com")
+	if !errors.Is(err, errBadEmail) {
+		t.Errorf("expected errBadEmail, got %v", err)
+	}
+	if s.Contains("e@f.net") || s.Len() != 2 {
+		t.Errorf("expected no elements to be added, got %v", s.String())
+	}
+	if err = s.TryAdd("e@f.net"); err != nil {
+		t.Error(err)
+	}
+	s.Delete("a@b.com")
+	check(sortedStr(s.ToSet()), s.Len(), "{\"c@d.org\" \"e@f.net\"}", 2, t)
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Error("unexpected nonempty")
+	}
+	u, err := NewValidated(validEmail, "", "x@y.z")
+	if err == nil || !u.IsEmpty() {
+		t.Errorf("expected error and empty set, got %v %v", err, u.String())
+	}
+}
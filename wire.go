@@ -0,0 +1,78 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+
+package set
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// SetWireVersion is the current [SetWire] format version.
+const SetWireVersion = 1
+
+// SetWire is the recommended representation of a set in RPC messages and
+// other wire formats: a version and the elements as a strictly ascending
+// slice, so that equal sets always encode identically (which makes them
+// cacheable, diffable, and hashable) and decoding can bulk-load a
+// [SortedSet] in O(n). It has JSON tags; the equivalent protobuf message
+// should keep the same field order and use scalar types with packed
+// repeated fields, e.g., for int64 elements:
+//
+//	message Int64Set {
+//		uint32 version = 1;
+//		repeated int64 elements = 2; // strictly ascending
+//	}
+//
+// Since protobuf omits zero values, a Version of 0 is treated as 1. Field
+// numbers must never be reused; add new fields with new numbers and bump
+// the version only for incompatible changes.
+type SetWire[E cmp.Ordered] struct {
+	Version  uint32 `json:"version"`
+	Elements []E    `json:"elements"`
+}
+
+// NewSetWire returns a SetWire of the current version containing the
+// given set's elements (which may be of any of the package's set types).
+func NewSetWire[E cmp.Ordered](s Interface[E]) SetWire[E] {
+	elements := make([]E, 0, s.Len())
+	for element := range s.All() {
+		elements = append(elements, element)
+	}
+	slices.Sort(elements) // fast if already sorted, e.g., from a SortedSet
+	return SetWire[E]{Version: SetWireVersion, Elements: elements}
+}
+
+// ToSet returns a new Set containing the SetWire's elements, or an error
+// if the SetWire's version isn't supported or its elements aren't strictly
+// ascending (i.e., it wasn't made by a conforming encoder).
+func (me *SetWire[E]) ToSet() (Set[E], error) {
+	if err := me.check(); err != nil {
+		return New[E](), err
+	}
+	return New(me.Elements...), nil
+}
+
+// ToSortedSet returns a new SortedSet containing the SetWire's elements,
+// built in O(n); or an error (and an empty SortedSet) as for
+// [SetWire.ToSet].
+func (me *SetWire[E]) ToSortedSet() (SortedSet[E], error) {
+	if err := me.check(); err != nil {
+		return NewSorted[E](), err
+	}
+	return NewSortedFromSorted(slices.Clone(me.Elements)), nil
+}
+
+func (me *SetWire[E]) check() error {
+	if me.Version > SetWireVersion {
+		return fmt.Errorf("cannot read set wire version %d (maximum %d)",
+			me.Version, SetWireVersion)
+	}
+	for i := 1; i < len(me.Elements); i++ {
+		if cmp.Compare(me.Elements[i-1], me.Elements[i]) >= 0 {
+			return fmt.Errorf("%w %v: set wire elements not strictly "+
+				"ascending at %d", ErrInvalidElement, me.Elements[i], i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright © 2024-25 Mark Summerfield. All rights reserved.
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSetWire(t *testing.T) {
+	s := New(30, 10, 20)
+	wire := NewSetWire[int](&s)
+	data, err := json.Marshal(wire)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := `{"version":1,"elements":[10,20,30]}`; string(data) != exp {
+		t.Errorf("expected %s, got %s", exp, data)
+	}
+	var back SetWire[int]
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	u, err := back.ToSet()
+	if err != nil || !u.Equal(s) {
+		t.Errorf("expected %v, got %v %v", s, u, err)
+	}
+	z, err := back.ToSortedSet()
+	check(z.String(), z.Len(), "{10 20 30}", 3, t)
+	if err != nil {
+		t.Error(err)
+	}
+	sorted := NewSorted(3, 1, 2)
+	if w := NewSetWire[int](&sorted); w.Elements[0] != 1 {
+		t.Errorf("unexpected elements %v", w.Elements)
+	}
+}
+
+func TestSetWireInvalid(t *testing.T) {
+	unset := SetWire[string]{Elements: []string{"a"}}
+	if s, err := unset.ToSet(); err != nil || !s.Contains("a") {
+		t.Errorf("expected version 0 to be accepted, got %v", err)
+	}
+	future := SetWire[string]{Version: 2}
+	if _, err := future.ToSet(); err == nil {
+		t.Error("expected unsupported version error")
+	}
+	unsorted := SetWire[string]{Version: 1, Elements: []string{"b", "a"}}
+	if _, err := unsorted.ToSortedSet(); !errors.Is(err,
+		ErrInvalidElement) {
+		t.Errorf("expected ErrInvalidElement, got %v", err)
+	}
+	dups := SetWire[int]{Version: 1, Elements: []int{1, 1}}
+	if _, err := dups.ToSet(); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("expected ErrInvalidElement, got %v", err)
+	}
+}